@@ -0,0 +1,73 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// AccumulatedPrecipitation accepts a location and returns the total forecast
+// rain and snow, in millimeters, across all forecast entries returned by the
+// weather API, covering the next five days.
+func (c *Client) AccumulatedPrecipitation(location string) (float64, error) {
+	apiURL, err := c.formAPIUrl("q", c.resolveLocation(location), maxForecastCount)
+	if err != nil {
+		return 0, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+
+	ar, _, err := c.queryForecast(context.Background(), apiURL)
+	if err != nil {
+		return 0, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+
+	var total float64
+	for _, entry := range ar.List {
+		total += entry.Rain.ThreeHour.value + entry.Snow.ThreeHour.value
+	}
+	return total, nil
+}
+
+// GrowingDegreeDays accepts a location and a base temperature in the unit set
+// in the weather client, and returns the growing degree days forecast for the
+// next five days. Each day contributes the average of its minimum and maximum
+// forecast temperatures less the base, or nothing if that is negative. Days
+// are in the time zone of the location, and the partial first and last days
+// of the forecast are included.
+func (c *Client) GrowingDegreeDays(location string, base float64) (float64, error) {
+	forecast, err := c.HourlyForecast(location, maxForecastCount)
+	if err != nil {
+		return 0, err
+	}
+
+	type dayRange struct {
+		min, max float64
+	}
+	var days []time.Time
+	ranges := make(map[time.Time]*dayRange)
+	for _, w := range forecast {
+		if w.Temperature == nil {
+			continue
+		}
+		y, m, d := w.Time.Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		r, ok := ranges[day]
+		if !ok {
+			days = append(days, day)
+			ranges[day] = &dayRange{min: *w.Temperature, max: *w.Temperature}
+			continue
+		}
+		r.min = math.Min(r.min, *w.Temperature)
+		r.max = math.Max(r.max, *w.Temperature)
+	}
+	if len(days) == 0 {
+		return 0, fmt.Errorf("the weather API did not return any temperatures for location %q", location)
+	}
+
+	var gdd float64
+	for _, day := range days {
+		r := ranges[day]
+		gdd += math.Max(0, (r.min+r.max)/2-base)
+	}
+	return gdd, nil
+}
@@ -0,0 +1,97 @@
+package weather_test
+
+import (
+	"testing"
+	"weather"
+)
+
+func TestAccumulatedPrecipitation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description, testDataFileName string
+		want                          float64
+	}{
+		{
+			description:      "rain only",
+			testDataFileName: "testdata/greatneck_8slots.json",
+			want:             1.29,
+		},
+		{
+			description:      "rain and snow",
+			testDataFileName: "testdata/greatneck_accumulated.json",
+			want:             2.79,
+		},
+		{
+			description:      "no precipitation",
+			testDataFileName: "testdata/greatneck.json",
+			want:             0,
+		},
+	}
+
+	for _, tc := range testCases {
+		ts := newTestServer(t, tc.testDataFileName)
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		got, err := wc.AccumulatedPrecipitation("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting accumulated precipitation, testing %v: %v", tc.description, err)
+		}
+		if roundTenth(got*10) != roundTenth(tc.want*10) {
+			t.Errorf("Want %v, got %v, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestGrowingDegreeDays(t *testing.T) {
+	t.Parallel()
+
+	// The test data covers 11pm April 10th, at 12.85 ºC, and April 11th from
+	// 11.15 ºC to 17.05 ºC, in the time zone of the location.
+	testCases := []struct {
+		description string
+		base, want  float64
+	}{
+		{
+			description: "base below both days",
+			base:        10,
+			want:        2.85 + 4.1,
+		},
+		{
+			description: "base above the first day",
+			base:        13,
+			want:        1.1,
+		},
+		{
+			description: "base above both days",
+			base:        20,
+			want:        0,
+		},
+	}
+
+	ts := newTestServer(t, "testdata/greatneck_accumulated.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+		weather.WithTempUnit(weather.TempUnitCelsius),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	for _, tc := range testCases {
+		got, err := wc.GrowingDegreeDays("Great Neck Plaza,NY,US", tc.base)
+		if err != nil {
+			t.Fatalf("Error while getting growing degree days, testing %v: %v", tc.description, err)
+		}
+		if roundTenth(got) != roundTenth(tc.want) {
+			t.Errorf("Want %v, got %v, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package weather
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// activityRule stores the conditions which suit an activity. Temperatures are
+// in Celsius, wind speed in meters/sec, and humidity and precipitation
+// probability in percent.
+type activityRule struct {
+	minTemp, maxTemp            float64
+	maxWindSpeed                float64
+	maxHumidity                 float64
+	maxPrecipitationProbability float64
+}
+
+// activityRules stores the conditions which suit each activity accepted by
+// ActivityScore.
+var activityRules = map[string]activityRule{
+	"running": {minTemp: 5, maxTemp: 22, maxWindSpeed: 8, maxHumidity: 80, maxPrecipitationProbability: 40},
+	"cycling": {minTemp: 10, maxTemp: 28, maxWindSpeed: 6, maxHumidity: 85, maxPrecipitationProbability: 30},
+	"beach":   {minTemp: 24, maxTemp: 35, maxWindSpeed: 7, maxHumidity: 90, maxPrecipitationProbability: 20},
+}
+
+// Points deducted from an activity score of 100, for each condition which
+// does not suit the activity.
+const (
+	activityTempPenalty          = 40
+	activityWindPenalty          = 25
+	activityHumidityPenalty      = 15
+	activityPrecipitationPenalty = 30
+)
+
+// ActivityScore returns a score from 0 to 100 for how well weather conditions
+// suit an activity, and a short reason such as "too windy, rain likely."
+// Activities are "running," "cycling," and "beach." Points are deducted for a
+// temperature outside of the range which suits the activity, and for wind,
+// humidity, or chance of precipitation above what suits it. Conditions which
+// are not present are not considered. A score of 0 and a reason naming the
+// valid activities is returned for an unknown activity.
+func ActivityScore(w Conditions, activity string) (int, string) {
+	rule, ok := activityRules[strings.ToLower(activity)]
+	if !ok {
+		names := make([]string, 0, len(activityRules))
+		for name := range activityRules {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return 0, fmt.Sprintf("unknown activity %q, please use one of %s", activity, strings.Join(names, ", "))
+	}
+
+	w = w.convert(TempUnitCelsius, SpeedUnitMeters)
+	score := 100
+	var reasons []string
+	if w.Temperature != nil && *w.Temperature < rule.minTemp {
+		score -= activityTempPenalty
+		reasons = append(reasons, "too cold")
+	}
+	if w.Temperature != nil && *w.Temperature > rule.maxTemp {
+		score -= activityTempPenalty
+		reasons = append(reasons, "too hot")
+	}
+	if w.WindSpeed != nil && *w.WindSpeed > rule.maxWindSpeed {
+		score -= activityWindPenalty
+		reasons = append(reasons, "too windy")
+	}
+	if w.Humidity != nil && *w.Humidity > rule.maxHumidity {
+		score -= activityHumidityPenalty
+		reasons = append(reasons, "too humid")
+	}
+	if w.PrecipitationProbability != nil && *w.PrecipitationProbability > rule.maxPrecipitationProbability {
+		score -= activityPrecipitationPenalty
+		reasons = append(reasons, "rain likely")
+	}
+
+	if score < 0 {
+		score = 0
+	}
+	if len(reasons) == 0 {
+		return score, "good conditions"
+	}
+	return score, strings.Join(reasons, ", ")
+}
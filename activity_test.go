@@ -0,0 +1,97 @@
+package weather_test
+
+import (
+	"strings"
+	"testing"
+	"weather"
+)
+
+func TestActivityScore(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		activity    string
+		conditions  weather.Conditions
+		want        int
+		wantReason  string
+	}{
+		{
+			description: "good for running",
+			activity:    "running",
+			conditions: weather.Conditions{
+				Temperature:              float64Ptr(55.4),
+				Humidity:                 float64Ptr(60),
+				WindSpeed:                float64Ptr(5.6),
+				PrecipitationProbability: float64Ptr(0),
+				TempUnit:                 weather.TempUnitFahrenheit,
+				SpeedUnit:                weather.SpeedUnitMiles,
+			},
+			want:       100,
+			wantReason: "good conditions",
+		},
+		{
+			description: "bad for running",
+			activity:    "Running",
+			conditions: weather.Conditions{
+				Temperature:              float64Ptr(30),
+				Humidity:                 float64Ptr(85),
+				WindSpeed:                float64Ptr(2.5),
+				PrecipitationProbability: float64Ptr(60),
+				TempUnit:                 weather.TempUnitCelsius,
+				SpeedUnit:                weather.SpeedUnitMeters,
+			},
+			want:       15,
+			wantReason: "too hot, too humid, rain likely",
+		},
+		{
+			description: "good for the beach",
+			activity:    "beach",
+			conditions: weather.Conditions{
+				Temperature: float64Ptr(302),
+				Humidity:    float64Ptr(70),
+				WindSpeed:   float64Ptr(3),
+				TempUnit:    weather.TempUnitKelvin,
+				SpeedUnit:   weather.SpeedUnitMeters,
+			},
+			want:       100,
+			wantReason: "good conditions",
+		},
+		{
+			description: "bad for the beach",
+			activity:    "beach",
+			conditions: weather.Conditions{
+				Temperature: float64Ptr(12.9),
+				WindSpeed:   float64Ptr(10),
+				TempUnit:    weather.TempUnitCelsius,
+				SpeedUnit:   weather.SpeedUnitMeters,
+			},
+			want:       35,
+			wantReason: "too cold, too windy",
+		},
+		{
+			description: "windy for cycling, in miles",
+			activity:    "cycling",
+			conditions: weather.Conditions{
+				Temperature: float64Ptr(68),
+				WindSpeed:   float64Ptr(20),
+				TempUnit:    weather.TempUnitFahrenheit,
+				SpeedUnit:   weather.SpeedUnitMiles,
+			},
+			want:       75,
+			wantReason: "too windy",
+		},
+	}
+
+	for _, tc := range testCases {
+		got, gotReason := weather.ActivityScore(tc.conditions, tc.activity)
+		if tc.want != got || tc.wantReason != gotReason {
+			t.Errorf("Want %d %q, got %d %q, testing %v", tc.want, tc.wantReason, got, gotReason, tc.description)
+		}
+	}
+
+	got, gotReason := weather.ActivityScore(weather.Conditions{}, "skydiving")
+	if got != 0 || !strings.Contains(gotReason, `unknown activity "skydiving"`) {
+		t.Errorf("Want 0 and an unknown activity reason, got %d %q", got, gotReason)
+	}
+}
@@ -0,0 +1,103 @@
+package weather
+
+import "math"
+
+// AggregateConditions returns a single set of conditions representing many,
+// such as the regional average of ForecastByBoundingBox results. Numeric
+// fields are averaged with equal weights, excluding entries where a field is
+// nil or NaN, and wind direction is averaged as a compass bearing so that
+// north winds either side of 0º average to north. The description and short
+// description are the most common ones, with ties going to the first seen.
+// Entries are converted to the units of the first entry, the time is the zero
+// time, and the result is stale if any entry is. The zero Conditions are
+// returned for no entries.
+func AggregateConditions(entries []Conditions) Conditions {
+	if len(entries) == 0 {
+		return Conditions{}
+	}
+
+	tempUnit, speedUnit := entries[0].TempUnit, entries[0].SpeedUnit
+	converted := make([]Conditions, len(entries))
+	for i, w := range entries {
+		converted[i] = w.convert(tempUnit, speedUnit)
+	}
+
+	average := func(field func(Conditions) *float64) *float64 {
+		var sum float64
+		var n int
+		for _, w := range converted {
+			v := field(w)
+			if v == nil || math.IsNaN(*v) {
+				continue
+			}
+			sum += *v
+			n++
+		}
+		if n == 0 {
+			return nil
+		}
+		avg := sum / float64(n)
+		return &avg
+	}
+
+	a := Conditions{
+		Description:              mostCommon(converted, func(w Conditions) *string { return w.Description }),
+		ShortDescription:         mostCommon(converted, func(w Conditions) *string { return w.ShortDescription }),
+		Temperature:              average(func(w Conditions) *float64 { return w.Temperature }),
+		FeelsLike:                average(func(w Conditions) *float64 { return w.FeelsLike }),
+		TempMin:                  average(func(w Conditions) *float64 { return w.TempMin }),
+		TempMax:                  average(func(w Conditions) *float64 { return w.TempMax }),
+		Humidity:                 average(func(w Conditions) *float64 { return w.Humidity }),
+		WindSpeed:                average(func(w Conditions) *float64 { return w.WindSpeed }),
+		WindDirection:            averageBearing(converted),
+		PrecipitationProbability: average(func(w Conditions) *float64 { return w.PrecipitationProbability }),
+		Latitude:                 average(func(w Conditions) *float64 { return w.Latitude }),
+		Longitude:                average(func(w Conditions) *float64 { return w.Longitude }),
+		TempUnit:                 tempUnit,
+		SpeedUnit:                speedUnit,
+	}
+	for _, w := range entries {
+		a.IsStale = a.IsStale || w.IsStale
+	}
+	return a
+}
+
+// mostCommon returns the most common value of a string field of conditions,
+// with ties going to the first seen, or nil if no conditions have the field.
+func mostCommon(entries []Conditions, field func(Conditions) *string) *string {
+	counts := make(map[string]int)
+	for _, w := range entries {
+		if v := field(w); v != nil {
+			counts[*v]++
+		}
+	}
+
+	var best *string
+	for _, w := range entries {
+		if v := field(w); v != nil && (best == nil || counts[*v] > counts[*best]) {
+			best = v
+		}
+	}
+	return best
+}
+
+// averageBearing returns the mean wind direction of conditions, in degrees
+// from 0 to 360, or nil if no conditions have a wind direction.
+func averageBearing(entries []Conditions) *float64 {
+	var x, y float64
+	var n int
+	for _, w := range entries {
+		if w.WindDirection == nil || math.IsNaN(*w.WindDirection) {
+			continue
+		}
+		rad := *w.WindDirection * math.Pi / 180
+		x += math.Cos(rad)
+		y += math.Sin(rad)
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	deg := math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+	return &deg
+}
@@ -0,0 +1,77 @@
+package weather_test
+
+import (
+	"math"
+	"testing"
+	"weather"
+)
+
+func TestAggregateConditions(t *testing.T) {
+	t.Parallel()
+
+	entries := []weather.Conditions{
+		{
+			Description:   stringPtr("light rain"),
+			Temperature:   float64Ptr(10),
+			Humidity:      float64Ptr(90),
+			WindSpeed:     float64Ptr(2),
+			WindDirection: float64Ptr(350),
+			TempUnit:      weather.TempUnitCelsius,
+			SpeedUnit:     weather.SpeedUnitMeters,
+		},
+		{
+			Description:   stringPtr("overcast clouds"),
+			Temperature:   float64Ptr(math.NaN()),
+			Humidity:      float64Ptr(80),
+			WindDirection: float64Ptr(10),
+			TempUnit:      weather.TempUnitCelsius,
+			SpeedUnit:     weather.SpeedUnitMeters,
+		},
+		{
+			Description: stringPtr("overcast clouds"),
+			Temperature: float64Ptr(293.15),
+			WindSpeed:   float64Ptr(math.NaN()),
+			TempUnit:    weather.TempUnitKelvin,
+			SpeedUnit:   weather.SpeedUnitMeters,
+		},
+		{
+			Description: stringPtr("light rain"),
+			WindSpeed:   float64Ptr(4),
+			TempUnit:    weather.TempUnitCelsius,
+			SpeedUnit:   weather.SpeedUnitMeters,
+			IsStale:     true,
+		},
+	}
+
+	// NaN and nil fields are excluded, and Kelvin is converted to the
+	// Celsius of the first entry.
+	want := weather.Conditions{
+		Description:   stringPtr("light rain"),
+		Temperature:   float64Ptr(15),
+		Humidity:      float64Ptr(85),
+		WindSpeed:     float64Ptr(3),
+		WindDirection: float64Ptr(0),
+		TempUnit:      weather.TempUnitCelsius,
+		SpeedUnit:     weather.SpeedUnitMeters,
+		IsStale:       true,
+	}
+
+	got := weather.AggregateConditions(entries)
+	// Wind direction is compared as a whole number of degrees, from 0 to 359.
+	if got.WindDirection != nil {
+		*got.WindDirection = math.Mod(math.Round(*got.WindDirection), 360)
+	}
+	if !want.Equal(got) {
+		t.Errorf("Want %+v, got %+v", want, got)
+	}
+
+	// A field which no entry has is nil.
+	if got.FeelsLike != nil {
+		t.Errorf("Want nil feels like temperature, got %v", *got.FeelsLike)
+	}
+
+	got = weather.AggregateConditions(nil)
+	if !got.Equal(weather.Conditions{}) {
+		t.Errorf("Want empty conditions for no entries, got %+v", got)
+	}
+}
@@ -0,0 +1,86 @@
+package weather
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// briefingTempUnitName stores how the TempUnit... constants are read aloud.
+var briefingTempUnitName = map[TempUnit]string{
+	TempUnitCelsius:    "degrees Celsius",
+	TempUnitFahrenheit: "degrees Fahrenheit",
+	TempUnitKelvin:     "Kelvin",
+}
+
+// briefingSpeedUnitName stores how the SpeedUnit... constants are read aloud.
+var briefingSpeedUnitName = map[SpeedUnit]string{
+	SpeedUnitMiles:  "miles per hour",
+	SpeedUnitMeters: "meters per second",
+}
+
+// Briefing returns weather conditions as a sentence suitable for reading
+// aloud, such as by a voice assistant, without symbols or abbreviations. For
+// example: "Currently overcast clouds, temperature fifty-five degrees
+// Fahrenheit, feels like fifty-five, humidity ninety-two percent, wind at six
+// miles per hour." Numbers are rounded to whole numbers and spelled out, and
+// fields which are not present are omitted.
+func (w Conditions) Briefing() string {
+	var parts []string
+	if w.Description != nil {
+		parts = append(parts, *w.Description)
+	}
+	if w.Temperature != nil {
+		parts = append(parts, "temperature "+numberToWords(*w.Temperature)+" "+briefingTempUnitName[w.TempUnit])
+	}
+	if w.FeelsLike != nil {
+		feelsLike := "feels like " + numberToWords(*w.FeelsLike)
+		if w.Temperature == nil {
+			feelsLike += " " + briefingTempUnitName[w.TempUnit]
+		}
+		parts = append(parts, feelsLike)
+	}
+	if w.Humidity != nil {
+		parts = append(parts, "humidity "+numberToWords(*w.Humidity)+" percent")
+	}
+	if w.WindSpeed != nil {
+		parts = append(parts, "wind at "+numberToWords(*w.WindSpeed)+" "+briefingSpeedUnitName[w.SpeedUnit])
+	}
+
+	if len(parts) == 0 {
+		return "No weather data is available."
+	}
+	return "Currently " + strings.Join(parts, ", ") + "."
+}
+
+var (
+	smallNumberWords = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+		"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	tensWords = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+)
+
+// numberToWords rounds f to a whole number and spells it out, such as
+// "fifty-five" or "minus three." Numbers from -200 to 200 are spelled out,
+// and others are returned as digits.
+func numberToWords(f float64) string {
+	n := int(math.Round(f))
+	switch {
+	case n < -200 || n > 200:
+		return strconv.Itoa(n)
+	case n < 0:
+		return "minus " + numberToWords(float64(-n))
+	case n < 20:
+		return smallNumberWords[n]
+	case n < 100:
+		if n%10 == 0 {
+			return tensWords[n/10]
+		}
+		return tensWords[n/10] + "-" + smallNumberWords[n%10]
+	}
+
+	words := smallNumberWords[n/100] + " hundred"
+	if n%100 != 0 {
+		words += " " + numberToWords(float64(n%100))
+	}
+	return words
+}
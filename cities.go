@@ -0,0 +1,65 @@
+package weather
+
+//go:generate go run gencities.go -list city.list.json.gz -names cities.txt -o cities.csv
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// citiesCSV maps the OpenWeatherMap.org IDs of well-known cities to names for
+// display. It is generated by gencities.go from the cities listed in
+// cities.txt, using the OpenWeatherMap.org city list.
+//
+//go:embed cities.csv
+var citiesCSV string
+
+var (
+	cityNamesOnce sync.Once
+	cityNames     map[int]string
+)
+
+// CityName returns the display name of a well-known city by its
+// OpenWeatherMap.org city ID, such as "London, GB" for 2643743, and false if
+// the ID is not one of the well-known cities embedded in this package.
+func CityName(id int) (string, bool) {
+	cityNamesOnce.Do(func() {
+		cityNames = parseCityNames(citiesCSV)
+	})
+	name, ok := cityNames[id]
+	return name, ok
+}
+
+// parseCityNames accepts CSV of city IDs and names, with a header line, and
+// returns the names keyed by ID. Lines which can not be parsed are skipped.
+func parseCityNames(data string) map[int]string {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil
+	}
+
+	names := make(map[int]string, len(records)-1)
+	for _, record := range records[1:] {
+		id, err := strconv.Atoi(record[0])
+		if err != nil {
+			continue
+		}
+		names[id] = record[1]
+	}
+	return names
+}
+
+// cityLabel returns a city ID for messages, followed by the name of the city
+// if it is well-known, such as "city ID 2643743 (London, GB)."
+func cityLabel(id int) string {
+	if name, ok := CityName(id); ok {
+		return fmt.Sprintf("city ID %d (%s)", id, name)
+	}
+	return fmt.Sprintf("city ID %d", id)
+}
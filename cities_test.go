@@ -0,0 +1,27 @@
+package weather_test
+
+import (
+	"testing"
+	"weather"
+)
+
+func TestCityName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		id     int
+		want   string
+		wantOK bool
+	}{
+		{id: 2643743, want: "London, GB", wantOK: true},
+		{id: 5128581, want: "New York, NY, US", wantOK: true},
+		{id: 1, want: "", wantOK: false},
+	}
+
+	for _, tc := range testCases {
+		got, ok := weather.CityName(tc.id)
+		if tc.want != got || tc.wantOK != ok {
+			t.Errorf("Want %q and %v, got %q and %v, testing city ID %d", tc.want, tc.wantOK, got, ok, tc.id)
+		}
+	}
+}
@@ -8,7 +8,9 @@ import (
 )
 
 func main() {
-	err := weather.RunCLI(os.Args[1:], os.Stdout, os.Stderr)
+	args, stopTestServer := startTestServer(os.Args[1:])
+	err := weather.RunCLI(args, os.Stdin, os.Stdout, os.Stderr)
+	stopTestServer()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"weather"
+)
+
+func TestStartTestServer(t *testing.T) {
+	// The test server needs no API key, and replaces the configured API host.
+	t.Setenv("OPENWEATHERMAP_API_KEY", "")
+	t.Setenv("WEATHERCASTER_API_HOST", "https://unused.example.com")
+
+	args, stop := startTestServer([]string{"-test-server", "-l", "London"})
+	t.Cleanup(stop)
+	if want := []string{"-l", "London"}; !reflect.DeepEqual(want, args) {
+		t.Errorf("Want arguments %q, got %q", want, args)
+	}
+
+	var output, errOutput bytes.Buffer
+	err := weather.RunCLI(args, strings.NewReader(""), &output, &errOutput)
+	if err != nil {
+		t.Fatalf("Error running CLI: %v, error output: %s", err, errOutput.String())
+	}
+
+	const want = "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph\n"
+	if want != output.String() {
+		t.Errorf("Want %q, got %q", want, output.String())
+	}
+}
+
+func TestStartTestServerNotSpecified(t *testing.T) {
+	t.Setenv("WEATHERCASTER_API_HOST", "https://unchanged.example.com")
+
+	args, stop := startTestServer([]string{"-l", "London", "--", "-test-server"})
+	t.Cleanup(stop)
+	if want := []string{"-l", "London", "--", "-test-server"}; !reflect.DeepEqual(want, args) {
+		t.Errorf("Want arguments %q, got %q", want, args)
+	}
+	if got := os.Getenv("WEATHERCASTER_API_HOST"); got != "https://unchanged.example.com" {
+		t.Errorf("Want WEATHERCASTER_API_HOST unchanged, got %q", got)
+	}
+}
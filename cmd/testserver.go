@@ -0,0 +1,56 @@
+package main
+
+import (
+	_ "embed"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+// testServerFlag is a hidden CLI flag, which starts a fake weather API
+// server. This is used to test the compiled CLI without an API key.
+const testServerFlag = "-test-server"
+
+// testServerForecast is served by the weather API test server.
+//
+//go:embed testdata/greatneck.json
+var testServerForecast []byte
+
+// testServerAPIKey is used by the test server when no API key is set, as the
+// test server does not check it.
+const testServerAPIKey = "00000000000000000000000000000000"
+
+// startTestServer accepts CLI arguments, and returns them without the
+// -test-server flag. If the flag was specified, a fake weather API server is
+// started which returns the forecast for Great Neck Plaza, NY for every
+// request, WEATHERCASTER_API_HOST is set to its URL, and
+// OPENWEATHERMAP_API_KEY is set if it is empty. The returned function stops
+// the server.
+func startTestServer(args []string) ([]string, func()) {
+	remaining := make([]string, 0, len(args))
+	var found bool
+	for i, arg := range args {
+		if arg == "--" {
+			remaining = append(remaining, args[i:]...)
+			break
+		}
+		if arg == testServerFlag || arg == "-"+testServerFlag {
+			found = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	if !found {
+		return remaining, func() {}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The response is not checked, as the CLI reports errors parsing it.
+		_, _ = w.Write(testServerForecast)
+	}))
+	os.Setenv("WEATHERCASTER_API_HOST", ts.URL)
+	if os.Getenv("OPENWEATHERMAP_API_KEY") == "" {
+		os.Setenv("OPENWEATHERMAP_API_KEY", testServerAPIKey)
+	}
+	return remaining, ts.Close
+}
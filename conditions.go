@@ -0,0 +1,603 @@
+package weather
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Conditions stores API-agnostic weather conditions. Temperatures are in the
+// unit of TempUnit, and wind speed is in the unit of SpeedUnit. Fields which
+// were not supplied by the weather API are nil.
+type Conditions struct {
+	Description *string `json:"description,omitempty"`
+	// ShortDescription is a category of weather, such as "Clouds."
+	ShortDescription *string  `json:"short_description,omitempty"`
+	Temperature      *float64 `json:"temperature,omitempty"`
+	FeelsLike        *float64 `json:"feels_like,omitempty"`
+	// TempMin and TempMax are only set when aggregating multiple forecast
+	// entries.
+	TempMin   *float64 `json:"temp_min,omitempty"`
+	TempMax   *float64 `json:"temp_max,omitempty"`
+	Humidity  *float64 `json:"humidity,omitempty"`
+	WindSpeed *float64 `json:"wind_speed,omitempty"`
+	// WindDirection is in degrees, where the wind is blowing from.
+	WindDirection *float64 `json:"wind_direction,omitempty"`
+	// PrecipitationProbability is the chance of precipitation, in percent.
+	PrecipitationProbability *float64 `json:"precipitation_probability,omitempty"`
+	Latitude                 *float64 `json:"latitude,omitempty"`
+	Longitude                *float64 `json:"longitude,omitempty"`
+	// Time is the time being forecast, and is the zero time if unknown.
+	Time      time.Time `json:"time"`
+	TempUnit  TempUnit  `json:"temp_unit"`
+	SpeedUnit SpeedUnit `json:"speed_unit"`
+	// IsStale is true when the weather API returned an error, and these are
+	// earlier conditions returned because of WithStaleIfError.
+	IsStale bool `json:"is_stale,omitempty"`
+}
+
+// JSON returns weather conditions as JSON. Fields which are not present are
+// omitted, and units are represented by name.
+func (w Conditions) JSON() ([]byte, error) {
+	return json.Marshal(w)
+}
+
+// JSONString returns weather conditions as a JSON string, for example for
+// use in templates.
+func (w Conditions) JSONString() (string, error) {
+	data, err := w.JSON()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// yamlConditions stores weather conditions with YAML keys matching the JSON
+// representation of Conditions, and units represented by name.
+type yamlConditions struct {
+	Description              *string   `yaml:"description,omitempty"`
+	ShortDescription         *string   `yaml:"short_description,omitempty"`
+	Temperature              *float64  `yaml:"temperature,omitempty"`
+	FeelsLike                *float64  `yaml:"feels_like,omitempty"`
+	TempMin                  *float64  `yaml:"temp_min,omitempty"`
+	TempMax                  *float64  `yaml:"temp_max,omitempty"`
+	Humidity                 *float64  `yaml:"humidity,omitempty"`
+	WindSpeed                *float64  `yaml:"wind_speed,omitempty"`
+	WindDirection            *float64  `yaml:"wind_direction,omitempty"`
+	PrecipitationProbability *float64  `yaml:"precipitation_probability,omitempty"`
+	Latitude                 *float64  `yaml:"latitude,omitempty"`
+	Longitude                *float64  `yaml:"longitude,omitempty"`
+	Time                     time.Time `yaml:"time"`
+	TempUnit                 string    `yaml:"temp_unit"`
+	SpeedUnit                string    `yaml:"speed_unit"`
+	IsStale                  bool      `yaml:"is_stale,omitempty"`
+}
+
+// MarshalYAML implements the Marshaler interface of YAML libraries such as
+// gopkg.in/yaml.v3, using the same keys as JSON. Fields which are not present
+// are omitted, and units are represented by name.
+func (w Conditions) MarshalYAML() (interface{}, error) {
+	return yamlConditions{
+		Description:              w.Description,
+		ShortDescription:         w.ShortDescription,
+		Temperature:              w.Temperature,
+		FeelsLike:                w.FeelsLike,
+		TempMin:                  w.TempMin,
+		TempMax:                  w.TempMax,
+		Humidity:                 w.Humidity,
+		WindSpeed:                w.WindSpeed,
+		WindDirection:            w.WindDirection,
+		PrecipitationProbability: w.PrecipitationProbability,
+		Latitude:                 w.Latitude,
+		Longitude:                w.Longitude,
+		Time:                     w.Time,
+		TempUnit:                 w.TempUnit.String(),
+		SpeedUnit:                w.SpeedUnit.String(),
+		IsStale:                  w.IsStale,
+	}, nil
+}
+
+// UnmarshalYAML implements the function-based Unmarshaler interface supported
+// by YAML libraries such as gopkg.in/yaml.v3, to read conditions written by
+// MarshalYAML, such as from a file. Units are read by name, as accepted by
+// ProcessCLITempUnit and ProcessCLISpeedUnit.
+func (w *Conditions) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var y yamlConditions
+	err := unmarshal(&y)
+	if err != nil {
+		return err
+	}
+
+	tempUnit, err := ProcessCLITempUnit(y.TempUnit)
+	if err != nil {
+		return err
+	}
+	speedUnit, err := ProcessCLISpeedUnit(y.SpeedUnit)
+	if err != nil {
+		return err
+	}
+
+	*w = Conditions{
+		Description:              y.Description,
+		ShortDescription:         y.ShortDescription,
+		Temperature:              y.Temperature,
+		FeelsLike:                y.FeelsLike,
+		TempMin:                  y.TempMin,
+		TempMax:                  y.TempMax,
+		Humidity:                 y.Humidity,
+		WindSpeed:                y.WindSpeed,
+		WindDirection:            y.WindDirection,
+		PrecipitationProbability: y.PrecipitationProbability,
+		Latitude:                 y.Latitude,
+		Longitude:                y.Longitude,
+		Time:                     y.Time,
+		TempUnit:                 tempUnit,
+		SpeedUnit:                speedUnit,
+		IsStale:                  y.IsStale,
+	}
+	return nil
+}
+
+// AsMap returns weather conditions as a map, such as for templating systems
+// and structured loggers. Keys are named as in JSON. Each numeric field which
+// is present has its value, in the units of the conditions, and a
+// "<key>_display" string formatted as in a forecast, such as "55.4 ºF."
+// Absent fields are omitted rather than included as nil, and every optional
+// field has a "has_<key>" bool saying whether it is present. The time is
+// omitted if it is unknown, and units are represented by name.
+func (w Conditions) AsMap() map[string]any {
+	m := map[string]any{
+		"temp_unit":  w.TempUnit.String(),
+		"speed_unit": w.SpeedUnit.String(),
+		"is_stale":   w.IsStale,
+		"has_time":   !w.Time.IsZero(),
+	}
+	if !w.Time.IsZero() {
+		m["time"] = w.Time
+	}
+
+	addString := func(key string, v *string) {
+		m["has_"+key] = v != nil
+		if v != nil {
+			m[key] = *v
+		}
+	}
+	addFloat := func(key string, v *float64, display string) {
+		m["has_"+key] = v != nil
+		if v != nil {
+			m[key] = *v
+			m[key+"_display"] = fmt.Sprintf(display, *v)
+		}
+	}
+	tempDisplay := "%.1f" + tempUnitName[w.TempUnit]
+
+	addString("description", w.Description)
+	addString("short_description", w.ShortDescription)
+	addFloat("temperature", w.Temperature, tempDisplay)
+	addFloat("feels_like", w.FeelsLike, tempDisplay)
+	addFloat("temp_min", w.TempMin, tempDisplay)
+	addFloat("temp_max", w.TempMax, tempDisplay)
+	addFloat("humidity", w.Humidity, "%.1f%%")
+	addFloat("wind_speed", w.WindSpeed, "%.1f "+speedUnitName[w.SpeedUnit])
+	addFloat("wind_direction", w.WindDirection, "%.0fº")
+	addFloat("precipitation_probability", w.PrecipitationProbability, "%.0f%%")
+	addFloat("latitude", w.Latitude, "%.4f")
+	addFloat("longitude", w.Longitude, "%.4f")
+	return m
+}
+
+// floatEpsilon is the largest difference between two floats which Equal
+// considers the same value.
+const floatEpsilon = 1e-9
+
+// Equal returns true if two weather conditions have the same values. Fields
+// which are nil in both are equal, a field which is nil in only one is not,
+// and floats within floatEpsilon of each other are equal. Times are compared
+// using time.Time.Equal, and units are not converted before comparing.
+func (w Conditions) Equal(other Conditions) bool {
+	equalString := func(a, b *string) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return *a == *b
+	}
+	equalFloat := func(a, b *float64) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return math.Abs(*a-*b) < floatEpsilon
+	}
+
+	return equalString(w.Description, other.Description) &&
+		equalString(w.ShortDescription, other.ShortDescription) &&
+		equalFloat(w.Temperature, other.Temperature) &&
+		equalFloat(w.FeelsLike, other.FeelsLike) &&
+		equalFloat(w.TempMin, other.TempMin) &&
+		equalFloat(w.TempMax, other.TempMax) &&
+		equalFloat(w.Humidity, other.Humidity) &&
+		equalFloat(w.WindSpeed, other.WindSpeed) &&
+		equalFloat(w.WindDirection, other.WindDirection) &&
+		equalFloat(w.PrecipitationProbability, other.PrecipitationProbability) &&
+		equalFloat(w.Latitude, other.Latitude) &&
+		equalFloat(w.Longitude, other.Longitude) &&
+		w.Time.Equal(other.Time) &&
+		w.TempUnit == other.TempUnit &&
+		w.SpeedUnit == other.SpeedUnit &&
+		w.IsStale == other.IsStale
+}
+
+// csvHeader stores the CSV columns of weather conditions, named as in JSON.
+var csvHeader = []string{"time", "description", "temperature", "feels_like", "humidity", "wind_speed", "wind_direction", "temp_unit", "speed_unit"}
+
+// csvFields returns weather conditions as CSV fields, in the order of
+// csvHeader. Fields which are not present are empty.
+func (w Conditions) csvFields() []string {
+	formatFloat := func(f *float64) string {
+		if f == nil {
+			return ""
+		}
+		return fmt.Sprintf("%.1f", *f)
+	}
+
+	var t, description string
+	if !w.Time.IsZero() {
+		t = w.Time.Format(time.RFC3339)
+	}
+	if w.Description != nil {
+		description = *w.Description
+	}
+
+	return []string{
+		t,
+		description,
+		formatFloat(w.Temperature),
+		formatFloat(w.FeelsLike),
+		formatFloat(w.Humidity),
+		formatFloat(w.WindSpeed),
+		formatFloat(w.WindDirection),
+		w.TempUnit.String(),
+		w.SpeedUnit.String(),
+	}
+}
+
+// ToCSVRow returns weather conditions as a CSV row, preceded by a header row
+// if includeHeader is true. The columns are time (RFC3339), description,
+// temperature, feels_like, humidity, wind_speed, wind_direction, temp_unit,
+// and speed_unit. Fields which are not present are empty, and each row ends
+// with a newline.
+func (w Conditions) ToCSVRow(includeHeader bool) string {
+	var b bytes.Buffer
+	cw := csv.NewWriter(&b)
+	if includeHeader {
+		// Writing to a bytes.Buffer does not fail.
+		_ = cw.Write(csvHeader)
+	}
+	_ = cw.Write(w.csvFields())
+	cw.Flush()
+	return b.String()
+}
+
+// tempFromKelvin converts a Kelvin temperature to the specified unit.
+func tempFromKelvin(kelvin float64, u TempUnit) float64 {
+	var t float64
+	switch u {
+	case TempUnitCelsius:
+		return kelvin - 273.15
+	case TempUnitFahrenheit:
+		return 1.8*(kelvin-273) + 32
+	case TempUnitKelvin:
+		// Input is already Kelvin
+		return kelvin
+	}
+	return t
+}
+
+// tempToKelvin converts a temperature in the specified unit to Kelvin.
+// This is the inverse of tempFromKelvin.
+func tempToKelvin(t float64, u TempUnit) float64 {
+	var kelvin float64
+	switch u {
+	case TempUnitCelsius:
+		return t + 273.15
+	case TempUnitFahrenheit:
+		return (t-32)/1.8 + 273
+	case TempUnitKelvin:
+		// Input is already Kelvin
+		return t
+	}
+	return kelvin
+}
+
+// speedFromMeters converts a speed in meters/sec to the specified unit.
+func speedFromMeters(meters float64, u SpeedUnit) float64 {
+	var s float64
+	switch u {
+	case SpeedUnitMeters:
+		// Input is already meters/sec
+		return meters
+	case SpeedUnitMiles:
+		return meters * 2.236936
+	}
+	return s
+}
+
+// speedToMeters converts a speed in the specified unit to meters/sec.
+// This is the inverse of speedFromMeters.
+func speedToMeters(s float64, u SpeedUnit) float64 {
+	var meters float64
+	switch u {
+	case SpeedUnitMeters:
+		// Input is already meters/sec
+		return s
+	case SpeedUnitMiles:
+		return s / 2.236936
+	}
+	return meters
+}
+
+// convert returns a copy of weather conditions with temperatures and wind
+// speed converted to the specified units.
+func (w Conditions) convert(tempUnit TempUnit, speedUnit SpeedUnit) Conditions {
+	convertTemp := func(t *float64) *float64 {
+		if t == nil {
+			return nil
+		}
+		converted := tempFromKelvin(tempToKelvin(*t, w.TempUnit), tempUnit)
+		return &converted
+	}
+
+	n := w
+	n.Temperature = convertTemp(w.Temperature)
+	n.FeelsLike = convertTemp(w.FeelsLike)
+	n.TempMin = convertTemp(w.TempMin)
+	n.TempMax = convertTemp(w.TempMax)
+	n.TempUnit = tempUnit
+
+	if w.WindSpeed != nil {
+		converted := speedFromMeters(speedToMeters(*w.WindSpeed, w.SpeedUnit), speedUnit)
+		n.WindSpeed = &converted
+	}
+	n.SpeedUnit = speedUnit
+	return n
+}
+
+// NormalizeTo returns a copy of weather conditions with temperatures and wind
+// speed converted to the specified units, such as to compare conditions from
+// weather clients using different units. Conditions carry their own units, so
+// no raw values are needed. If either unit is not one of the TempUnit... or
+// SpeedUnit... constants, the conditions are returned unchanged.
+func NormalizeTo(w Conditions, tempUnit TempUnit, speedUnit SpeedUnit) Conditions {
+	_, validTemp := tempUnitName[tempUnit]
+	_, validSpeed := speedUnitName[speedUnit]
+	if !validTemp || !validSpeed {
+		return w
+	}
+	return w.convert(tempUnit, speedUnit)
+}
+
+// primaryTempThreshold is how many degrees Celsius (or Kelvin) the
+// feels-like temperature must differ from the actual temperature, for
+// PrimaryTemperature to prefer it.
+const primaryTempThreshold = 3.0
+
+// PrimaryTemperature returns the temperature which best represents comfort,
+// and a label for it. This is the feels-like temperature, labeled "feels
+// like," when it differs from the actual temperature by more than 3 ºC (5.4
+// ºF); otherwise it is the actual temperature, labeled "temp." If only one
+// temperature is present it is returned, and if neither is present the label
+// is empty.
+func (w Conditions) PrimaryTemperature() (float64, string) {
+	switch {
+	case w.Temperature == nil && w.FeelsLike == nil:
+		return 0, ""
+	case w.FeelsLike == nil:
+		return *w.Temperature, "temp"
+	case w.Temperature == nil:
+		return *w.FeelsLike, "feels like"
+	}
+
+	diff := *w.FeelsLike - *w.Temperature
+	if w.TempUnit == TempUnitFahrenheit {
+		diff /= 1.8
+	}
+	if diff > primaryTempThreshold || diff < -primaryTempThreshold {
+		return *w.FeelsLike, "feels like"
+	}
+	return *w.Temperature, "temp"
+}
+
+// Thresholds for health warnings, in degrees Fahrenheit, as used by public
+// health officials for wind chill and heat index.
+const (
+	coldWarningFahrenheit = 0.0
+	heatWarningFahrenheit = 103.0
+)
+
+// apparentFahrenheit returns the feels-like temperature, which accounts for
+// wind chill and heat index, or else the actual temperature, in degrees
+// Fahrenheit. False is returned if neither temperature is present.
+func (w Conditions) apparentFahrenheit() (float64, bool) {
+	t := w.FeelsLike
+	if t == nil {
+		t = w.Temperature
+	}
+	if t == nil {
+		return 0, false
+	}
+	return tempFromKelvin(tempToKelvin(*t, w.TempUnit), TempUnitFahrenheit), true
+}
+
+// ColdWarning returns true if the feels-like temperature, or the actual
+// temperature if there is no feels-like temperature, is below 0 ºF.
+func (w Conditions) ColdWarning() bool {
+	f, ok := w.apparentFahrenheit()
+	return ok && f < coldWarningFahrenheit
+}
+
+// HeatWarning returns true if the feels-like temperature, or the actual
+// temperature if there is no feels-like temperature, is above 103 ºF.
+func (w Conditions) HeatWarning() bool {
+	f, ok := w.apparentFahrenheit()
+	return ok && f > heatWarningFahrenheit
+}
+
+// THI returns the Temperature-Humidity Index, used in livestock management to
+// gauge heat stress, or NaN if the temperature or humidity is not present.
+// THI = 0.8 * T + (humidity / 100) * (T - 14.4) + 46.4, where T is the
+// temperature in degrees Celsius.
+func (w Conditions) THI() float64 {
+	if w.Temperature == nil || w.Humidity == nil {
+		return math.NaN()
+	}
+	t := tempFromKelvin(tempToKelvin(*w.Temperature, w.TempUnit), TempUnitCelsius)
+	return 0.8*t + (*w.Humidity/100)*(t-14.4) + 46.4
+}
+
+// THICategory describes the heat stress of cattle, using the Livestock
+// Weather Safety Index thresholds of NOAA for THI: "Normal" below 75, "Alert"
+// from 75 to below 79, "Danger" from 79 to below 84, and "Emergency" from 84.
+// An empty string is returned if THI is not available.
+func (w Conditions) THICategory() string {
+	switch thi := w.THI(); {
+	case math.IsNaN(thi):
+		return ""
+	case thi < 75:
+		return "Normal"
+	case thi < 79:
+		return "Alert"
+	case thi < 84:
+		return "Danger"
+	default:
+		return "Emergency"
+	}
+}
+
+// influxEscaper escapes measurement names, and tag keys and values, for the
+// InfluxDB line protocol.
+var influxEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// ToInfluxLineProtocol returns weather conditions as an InfluxDB line protocol
+// line, using the specified measurement name and a location tag. Only fields
+// which are present are included, and an empty string is returned if there
+// are none. The timestamp is the forecast time, or the current time if the
+// forecast time is unknown.
+func (w Conditions) ToInfluxLineProtocol(measurement, location string) string {
+	var fields []string
+	addField := func(name string, v *float64) {
+		if v != nil {
+			fields = append(fields, fmt.Sprintf("%s=%.1f", name, *v))
+		}
+	}
+	addField("temperature", w.Temperature)
+	addField("feels_like", w.FeelsLike)
+	addField("humidity", w.Humidity)
+	addField("wind_speed", w.WindSpeed)
+
+	if len(fields) == 0 {
+		return ""
+	}
+
+	t := w.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	return fmt.Sprintf("%s,location=%s %s %d",
+		influxEscaper.Replace(measurement), influxEscaper.Replace(location), strings.Join(fields, ","), t.UnixNano())
+}
+
+// prometheusSpeedUnit stores Prometheus metric name suffixes for the
+// SpeedUnit... constants.
+var prometheusSpeedUnit = map[SpeedUnit]string{
+	SpeedUnitMiles:  "miles_per_hour",
+	SpeedUnitMeters: "meters_per_second",
+}
+
+// prometheusEscaper escapes label values for the Prometheus text format.
+var prometheusEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// ToPrometheusText returns weather conditions in the Prometheus text
+// exposition format, with one gauge per field which is present. Each metric
+// has a location label, and metric names include the unit of measure.
+func (w Conditions) ToPrometheusText(location string) string {
+	var b strings.Builder
+	label := prometheusEscaper.Replace(location)
+
+	addGauge := func(name, help string, v *float64) {
+		if v == nil {
+			return
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", name)
+		fmt.Fprintf(&b, "%s{location=\"%s\"} %.1f\n", name, label, *v)
+	}
+	addGauge("weather_temperature_"+w.TempUnit.String(), "Current temperature", w.Temperature)
+	addGauge("weather_feels_like_"+w.TempUnit.String(), "Current apparent temperature", w.FeelsLike)
+	addGauge("weather_humidity_percent", "Current relative humidity", w.Humidity)
+	addGauge("weather_wind_speed_"+prometheusSpeedUnit[w.SpeedUnit], "Current wind speed", w.WindSpeed)
+
+	return b.String()
+}
+
+// slackText stores a Slack Block Kit text object.
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackBlock stores a Slack Block Kit layout block.
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+// slackEscaper escapes the characters which Slack treats as control
+// characters in message text.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// ToSlack returns weather conditions as a Slack Block Kit message in JSON,
+// such as for posting to a Slack webhook. The message has a header with the
+// location, a divider, and a section with fields for temperature, humidity,
+// wind, and description. Fields which are not present are omitted, as is the
+// section if there are none.
+func (w Conditions) ToSlack(location string) string {
+	var fields []slackText
+	addField := func(name, value string) {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: "*" + name + "*\n" + slackEscaper.Replace(value)})
+	}
+	if w.Temperature != nil {
+		addField("Temperature", fmt.Sprintf("%.1f%v", *w.Temperature, tempUnitName[w.TempUnit]))
+	}
+	if w.Humidity != nil {
+		addField("Humidity", fmt.Sprintf("%.1f%%", *w.Humidity))
+	}
+	if w.WindSpeed != nil {
+		addField("Wind", fmt.Sprintf("%.1f %v", *w.WindSpeed, speedUnitName[w.SpeedUnit]))
+	}
+	if w.Description != nil {
+		addField("Description", *w.Description)
+	}
+
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: location}},
+		{Type: "divider"},
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, slackBlock{Type: "section", Fields: fields})
+	}
+
+	// Text is already escaped for Slack, so JSON does not also need to escape
+	// HTML. Encoding these types to a bytes.Buffer does not fail.
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(struct {
+		Blocks []slackBlock `json:"blocks"`
+	}{blocks})
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,667 @@
+package weather_test
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"weather"
+)
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// roundTenth rounds to one decimal place, as numbers are formatted in a
+// forecast.
+func roundTenth(f float64) float64 {
+	return math.Round(f*10) / 10
+}
+
+// greatNeckConditions returns conditions for testdata/greatneck.json, in the
+// default units of a weather client.
+func greatNeckConditions(t *testing.T) weather.Conditions {
+	t.Helper()
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	w, err := wc.ForecastConditions("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting forecast conditions: %v", err)
+	}
+	return w
+}
+
+func TestToInfluxLineProtocol(t *testing.T) {
+	t.Parallel()
+
+	w := greatNeckConditions(t)
+
+	const want = `weather,location=Great\ Neck\ Plaza\,NY\,US temperature=55.4,feels_like=54.9,humidity=92.0,wind_speed=5.6 1618110000000000000`
+	got := w.ToInfluxLineProtocol("weather", "Great Neck Plaza,NY,US")
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+
+	// Absent fields are omitted.
+	w.FeelsLike = nil
+	w.WindSpeed = nil
+	const wantPartial = `weather,location=London temperature=55.4,humidity=92.0 1618110000000000000`
+	got = w.ToInfluxLineProtocol("weather", "London")
+	if wantPartial != got {
+		t.Errorf("Want %q, got %q", wantPartial, got)
+	}
+
+	// Without a forecast time, the current time is used.
+	w = weather.Conditions{Temperature: w.Temperature}
+	got = w.ToInfluxLineProtocol("weather", "London")
+	if !strings.HasPrefix(got, "weather,location=London temperature=55.4 ") || strings.HasSuffix(got, " 1618110000000000000") {
+		t.Errorf("Want a line using the current time, got %q", got)
+	}
+
+	// Without any fields, there is no line.
+	w = weather.Conditions{}
+	got = w.ToInfluxLineProtocol("weather", "London")
+	if got != "" {
+		t.Errorf("Want an empty string for conditions without fields, got %q", got)
+	}
+}
+
+func TestToPrometheusText(t *testing.T) {
+	t.Parallel()
+
+	w := greatNeckConditions(t)
+
+	want := `# HELP weather_temperature_fahrenheit Current temperature
+# TYPE weather_temperature_fahrenheit gauge
+weather_temperature_fahrenheit{location="Great Neck \"Plaza\""} 55.4
+# HELP weather_feels_like_fahrenheit Current apparent temperature
+# TYPE weather_feels_like_fahrenheit gauge
+weather_feels_like_fahrenheit{location="Great Neck \"Plaza\""} 54.9
+# HELP weather_humidity_percent Current relative humidity
+# TYPE weather_humidity_percent gauge
+weather_humidity_percent{location="Great Neck \"Plaza\""} 92.0
+# HELP weather_wind_speed_miles_per_hour Current wind speed
+# TYPE weather_wind_speed_miles_per_hour gauge
+weather_wind_speed_miles_per_hour{location="Great Neck \"Plaza\""} 5.6
+`
+	got := w.ToPrometheusText(`Great Neck "Plaza"`)
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+}
+
+func TestConditionsJSON(t *testing.T) {
+	t.Parallel()
+
+	w := weather.Conditions{
+		Description: stringPtr("overcast clouds"),
+		Temperature: float64Ptr(12.9),
+		Humidity:    float64Ptr(92),
+		WindSpeed:   float64Ptr(2.5),
+		Time:        time.Unix(1618110000, 0).UTC(),
+		TempUnit:    weather.TempUnitCelsius,
+		SpeedUnit:   weather.SpeedUnitMeters,
+	}
+
+	// Absent fields are omitted.
+	const want = `{"description":"overcast clouds","temperature":12.9,"humidity":92,"wind_speed":2.5,"time":"2021-04-11T03:00:00Z","temp_unit":"celsius","speed_unit":"meters"}`
+	got, err := w.JSONString()
+	if err != nil {
+		t.Fatalf("Error converting conditions to JSON: %v", err)
+	}
+	if want != got {
+		t.Errorf("Want %s, got %s", want, got)
+	}
+
+	gotBytes, err := w.JSON()
+	if err != nil {
+		t.Fatalf("Error converting conditions to JSON: %v", err)
+	}
+	if want != string(gotBytes) {
+		t.Errorf("Want %s, got %s", want, gotBytes)
+	}
+}
+
+func TestPrimaryTemperature(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		conditions  weather.Conditions
+		want        float64
+		wantLabel   string
+	}{
+		{
+			description: "feels like close to temp",
+			conditions:  weather.Conditions{Temperature: float64Ptr(55.4), FeelsLike: float64Ptr(54.9), TempUnit: weather.TempUnitFahrenheit},
+			want:        55.4,
+			wantLabel:   "temp",
+		},
+		{
+			description: "feels like much colder than temp",
+			conditions:  weather.Conditions{Temperature: float64Ptr(35), FeelsLike: float64Ptr(24), TempUnit: weather.TempUnitFahrenheit},
+			want:        24,
+			wantLabel:   "feels like",
+		},
+		{
+			description: "feels like much warmer than temp",
+			conditions:  weather.Conditions{Temperature: float64Ptr(30), FeelsLike: float64Ptr(35), TempUnit: weather.TempUnitCelsius},
+			want:        35,
+			wantLabel:   "feels like",
+		},
+		{
+			description: "4 degrees is within the threshold in Fahrenheit",
+			conditions:  weather.Conditions{Temperature: float64Ptr(80), FeelsLike: float64Ptr(84), TempUnit: weather.TempUnitFahrenheit},
+			want:        80,
+			wantLabel:   "temp",
+		},
+		{
+			description: "4 degrees is beyond the threshold in Celsius",
+			conditions:  weather.Conditions{Temperature: float64Ptr(20), FeelsLike: float64Ptr(16), TempUnit: weather.TempUnitCelsius},
+			want:        16,
+			wantLabel:   "feels like",
+		},
+		{
+			description: "no feels like",
+			conditions:  weather.Conditions{Temperature: float64Ptr(20), TempUnit: weather.TempUnitCelsius},
+			want:        20,
+			wantLabel:   "temp",
+		},
+		{
+			description: "no temperatures",
+			conditions:  weather.Conditions{},
+			want:        0,
+			wantLabel:   "",
+		},
+	}
+
+	for _, tc := range testCases {
+		got, gotLabel := tc.conditions.PrimaryTemperature()
+		if tc.want != got || tc.wantLabel != gotLabel {
+			t.Errorf("Want %v %q, got %v %q, testing %v", tc.want, tc.wantLabel, got, gotLabel, tc.description)
+		}
+	}
+}
+
+func TestConditionsEqual(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		a, b        weather.Conditions
+		want        bool
+	}{
+		{
+			description: "both nil",
+			a:           weather.Conditions{},
+			b:           weather.Conditions{},
+			want:        true,
+		},
+		{
+			description: "first nil",
+			a:           weather.Conditions{},
+			b:           weather.Conditions{Temperature: float64Ptr(12.9)},
+			want:        false,
+		},
+		{
+			description: "second nil",
+			a:           weather.Conditions{Temperature: float64Ptr(12.9)},
+			b:           weather.Conditions{},
+			want:        false,
+		},
+		{
+			description: "both set to the same value at different addresses",
+			a:           weather.Conditions{Description: stringPtr("overcast clouds"), Temperature: float64Ptr(12.9)},
+			b:           weather.Conditions{Description: stringPtr("overcast clouds"), Temperature: float64Ptr(12.9)},
+			want:        true,
+		},
+		{
+			description: "both set to different values",
+			a:           weather.Conditions{Temperature: float64Ptr(12.9)},
+			b:           weather.Conditions{Temperature: float64Ptr(13)},
+			want:        false,
+		},
+		{
+			description: "different descriptions",
+			a:           weather.Conditions{Description: stringPtr("overcast clouds")},
+			b:           weather.Conditions{Description: stringPtr("light rain")},
+			want:        false,
+		},
+		{
+			description: "description nil in one",
+			a:           weather.Conditions{Description: stringPtr("overcast clouds")},
+			b:           weather.Conditions{},
+			want:        false,
+		},
+		{
+			description: "floats within the tolerance",
+			a:           weather.Conditions{WindSpeed: float64Ptr(2.5)},
+			b:           weather.Conditions{WindSpeed: float64Ptr(2.5 + 0.5e-9)},
+			want:        true,
+		},
+		{
+			description: "floats beyond the tolerance",
+			a:           weather.Conditions{WindSpeed: float64Ptr(2.5)},
+			b:           weather.Conditions{WindSpeed: float64Ptr(2.5 + 2e-9)},
+			want:        false,
+		},
+		{
+			description: "same time in different time zones",
+			a:           weather.Conditions{Time: time.Unix(1618110000, 0).UTC()},
+			b:           weather.Conditions{Time: time.Unix(1618110000, 0).In(time.FixedZone("", -14400))},
+			want:        true,
+		},
+		{
+			description: "different units",
+			a:           weather.Conditions{TempUnit: weather.TempUnitCelsius},
+			b:           weather.Conditions{TempUnit: weather.TempUnitKelvin},
+			want:        false,
+		},
+	}
+
+	for _, tc := range testCases {
+		got := tc.a.Equal(tc.b)
+		if tc.want != got {
+			t.Errorf("Want %v, got %v, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestToCSVRow(t *testing.T) {
+	t.Parallel()
+
+	w := weather.Conditions{
+		Description: stringPtr("rain, heavy at times"),
+		Temperature: float64Ptr(12.9),
+		Humidity:    float64Ptr(92),
+		WindSpeed:   float64Ptr(2.5),
+		Time:        time.Unix(1618110000, 0).In(time.FixedZone("", -14400)),
+		TempUnit:    weather.TempUnitCelsius,
+		SpeedUnit:   weather.SpeedUnitMeters,
+	}
+
+	// Absent fields are empty, and the description is quoted.
+	const wantRow = "2021-04-10T23:00:00-04:00,\"rain, heavy at times\",12.9,,92.0,2.5,,celsius,meters\n"
+	got := w.ToCSVRow(false)
+	if wantRow != got {
+		t.Errorf("Want %q, got %q", wantRow, got)
+	}
+
+	const wantHeader = "time,description,temperature,feels_like,humidity,wind_speed,wind_direction,temp_unit,speed_unit\n"
+	got = w.ToCSVRow(true)
+	if wantHeader+wantRow != got {
+		t.Errorf("Want %q, got %q", wantHeader+wantRow, got)
+	}
+}
+
+func TestBriefing(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		conditions  weather.Conditions
+		want        string
+	}{
+		{
+			description: "all fields",
+			conditions:  greatNeckConditions(t),
+			want:        "Currently overcast clouds, temperature fifty-five degrees Fahrenheit, feels like fifty-five, humidity ninety-two percent, wind at six miles per hour.",
+		},
+		{
+			description: "below zero in Celsius",
+			conditions:  weather.Conditions{Temperature: float64Ptr(-12.6), TempUnit: weather.TempUnitCelsius, WindSpeed: float64Ptr(0.2), SpeedUnit: weather.SpeedUnitMeters},
+			want:        "Currently temperature minus thirteen degrees Celsius, wind at zero meters per second.",
+		},
+		{
+			description: "hundreds in Kelvin",
+			conditions:  weather.Conditions{Temperature: float64Ptr(140), FeelsLike: float64Ptr(199.6), TempUnit: weather.TempUnitKelvin},
+			want:        "Currently temperature one hundred forty Kelvin, feels like two hundred.",
+		},
+		{
+			description: "beyond two hundred",
+			conditions:  weather.Conditions{FeelsLike: float64Ptr(286.2), TempUnit: weather.TempUnitKelvin},
+			want:        "Currently feels like 286 Kelvin.",
+		},
+		{
+			description: "no fields",
+			conditions:  weather.Conditions{},
+			want:        "No weather data is available.",
+		},
+	}
+
+	for _, tc := range testCases {
+		got := tc.conditions.Briefing()
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestNormalizeTo(t *testing.T) {
+	t.Parallel()
+
+	celsius := weather.Conditions{
+		Description: stringPtr("overcast clouds"),
+		Temperature: float64Ptr(12.85),
+		FeelsLike:   float64Ptr(12.6),
+		Humidity:    float64Ptr(92),
+		WindSpeed:   float64Ptr(2.5),
+		TempUnit:    weather.TempUnitCelsius,
+		SpeedUnit:   weather.SpeedUnitMeters,
+	}
+
+	testCases := []struct {
+		description                   string
+		tempUnit                      weather.TempUnit
+		speedUnit                     weather.SpeedUnit
+		wantTemp, wantFeels, wantWind float64
+	}{
+		{
+			description: "Celsius to Kelvin",
+			tempUnit:    weather.TempUnitKelvin,
+			speedUnit:   weather.SpeedUnitMeters,
+			wantTemp:    286,
+			wantFeels:   285.8,
+			wantWind:    2.5,
+		},
+		{
+			description: "Celsius to Fahrenheit",
+			tempUnit:    weather.TempUnitFahrenheit,
+			speedUnit:   weather.SpeedUnitMiles,
+			wantTemp:    55.4,
+			wantFeels:   55,
+			wantWind:    5.6,
+		},
+		{
+			description: "invalid unit leaves conditions unchanged",
+			tempUnit:    weather.TempUnit(99),
+			speedUnit:   weather.SpeedUnitMiles,
+			wantTemp:    12.85,
+			wantFeels:   12.6,
+			wantWind:    2.5,
+		},
+	}
+
+	for _, tc := range testCases {
+		got := weather.NormalizeTo(celsius, tc.tempUnit, tc.speedUnit)
+		if roundTenth(tc.wantTemp) != roundTenth(*got.Temperature) {
+			t.Errorf("Want temperature %v, got %v, testing %v", tc.wantTemp, *got.Temperature, tc.description)
+		}
+		if roundTenth(tc.wantFeels) != roundTenth(*got.FeelsLike) {
+			t.Errorf("Want feels like %v, got %v, testing %v", tc.wantFeels, *got.FeelsLike, tc.description)
+		}
+		if roundTenth(tc.wantWind) != roundTenth(*got.WindSpeed) {
+			t.Errorf("Want wind speed %v, got %v, testing %v", tc.wantWind, *got.WindSpeed, tc.description)
+		}
+		if *got.Humidity != 92 || *got.Description != "overcast clouds" {
+			t.Errorf("Want humidity and description unchanged, got %v and %q, testing %v", *got.Humidity, *got.Description, tc.description)
+		}
+	}
+
+	if *celsius.Temperature != 12.85 || celsius.TempUnit != weather.TempUnitCelsius {
+		t.Errorf("Want the original conditions unchanged, got %+v", celsius)
+	}
+}
+
+func TestHealthWarnings(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description       string
+		conditions        weather.Conditions
+		wantCold, wantHot bool
+	}{
+		{
+			description: "mild",
+			conditions:  weather.Conditions{Temperature: float64Ptr(55.4), FeelsLike: float64Ptr(54.9), TempUnit: weather.TempUnitFahrenheit},
+		},
+		{
+			description: "wind chill below 0 ºF",
+			conditions:  weather.Conditions{Temperature: float64Ptr(10), FeelsLike: float64Ptr(-5), TempUnit: weather.TempUnitFahrenheit},
+			wantCold:    true,
+		},
+		{
+			description: "heat index above 103 ºF in Celsius",
+			conditions:  weather.Conditions{Temperature: float64Ptr(36), FeelsLike: float64Ptr(41), TempUnit: weather.TempUnitCelsius},
+			wantHot:     true,
+		},
+		{
+			description: "feels like takes precedence over temperature",
+			conditions:  weather.Conditions{Temperature: float64Ptr(105), FeelsLike: float64Ptr(100), TempUnit: weather.TempUnitFahrenheit},
+		},
+		{
+			description: "temperature without feels like",
+			conditions:  weather.Conditions{Temperature: float64Ptr(250), TempUnit: weather.TempUnitKelvin},
+			wantCold:    true,
+		},
+		{
+			description: "no temperatures",
+			conditions:  weather.Conditions{},
+		},
+	}
+
+	for _, tc := range testCases {
+		gotCold, gotHot := tc.conditions.ColdWarning(), tc.conditions.HeatWarning()
+		if tc.wantCold != gotCold || tc.wantHot != gotHot {
+			t.Errorf("Want cold %v and heat %v warnings, got %v and %v, testing %v", tc.wantCold, tc.wantHot, gotCold, gotHot, tc.description)
+		}
+	}
+}
+
+func TestAsMap(t *testing.T) {
+	t.Parallel()
+
+	want := map[string]any{
+		"description":                       "overcast clouds",
+		"short_description":                 "Clouds",
+		"temperature":                       55.4,
+		"temperature_display":               "55.4 ºF",
+		"feels_like":                        54.9,
+		"feels_like_display":                "54.9 ºF",
+		"humidity":                          92.0,
+		"humidity_display":                  "92.0%",
+		"wind_speed":                        5.6,
+		"wind_speed_display":                "5.6 mph",
+		"wind_direction":                    180.0,
+		"wind_direction_display":            "180º",
+		"precipitation_probability":         0.0,
+		"precipitation_probability_display": "0%",
+		"latitude":                          40.8,
+		"latitude_display":                  "40.7868",
+		"longitude":                         -73.7,
+		"longitude_display":                 "-73.7265",
+		"time":                              time.Unix(1618110000, 0),
+		"temp_unit":                         "fahrenheit",
+		"speed_unit":                        "miles",
+		"is_stale":                          false,
+		"has_description":                   true,
+		"has_short_description":             true,
+		"has_temperature":                   true,
+		"has_feels_like":                    true,
+		"has_temp_min":                      false,
+		"has_temp_max":                      false,
+		"has_humidity":                      true,
+		"has_wind_speed":                    true,
+		"has_wind_direction":                true,
+		"has_precipitation_probability":     true,
+		"has_latitude":                      true,
+		"has_longitude":                     true,
+		"has_time":                          true,
+	}
+
+	got := greatNeckConditions(t).AsMap()
+	if len(want) != len(got) {
+		t.Errorf("Want %d keys, got %d: %v", len(want), len(got), got)
+	}
+	for key, wantValue := range want {
+		gotValue, ok := got[key]
+		if !ok {
+			t.Errorf("Want key %q, got none", key)
+			continue
+		}
+
+		var equal bool
+		switch v := wantValue.(type) {
+		case float64:
+			f, isFloat := gotValue.(float64)
+			equal = isFloat && v == roundTenth(f)
+		case time.Time:
+			tm, isTime := gotValue.(time.Time)
+			equal = isTime && v.Equal(tm)
+		default:
+			equal = wantValue == gotValue
+		}
+		if !equal {
+			t.Errorf("Want %#v, got %#v, testing key %q", wantValue, gotValue, key)
+		}
+	}
+}
+
+func TestConditionsYAML(t *testing.T) {
+	t.Parallel()
+
+	want := greatNeckConditions(t)
+	want.IsStale = true
+
+	marshaled, err := want.MarshalYAML()
+	if err != nil {
+		t.Fatalf("Error marshaling conditions to YAML: %v", err)
+	}
+
+	// YAML keys match JSON keys.
+	v := reflect.ValueOf(marshaled)
+	wantKeys := map[string]bool{"description": true, "temp_unit": true, "speed_unit": true, "is_stale": true, "temp_min": true}
+	for i := 0; i < v.NumField(); i++ {
+		key := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		delete(wantKeys, key)
+	}
+	if len(wantKeys) > 0 {
+		t.Errorf("Want YAML keys %v, got none in %+v", wantKeys, marshaled)
+	}
+	if v.FieldByName("TempUnit").Interface() != "fahrenheit" {
+		t.Errorf("Want the temperature unit marshaled by name, got %v", v.FieldByName("TempUnit"))
+	}
+
+	// Simulate a YAML library decoding what was marshaled.
+	unmarshal := func(out interface{}) error {
+		reflect.ValueOf(out).Elem().Set(v)
+		return nil
+	}
+	var got weather.Conditions
+	err = got.UnmarshalYAML(unmarshal)
+	if err != nil {
+		t.Fatalf("Error unmarshaling conditions from YAML: %v", err)
+	}
+	if !want.Equal(got) {
+		t.Errorf("Want %+v, got %+v", want, got)
+	}
+}
+
+func TestTHI(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description  string
+		conditions   weather.Conditions
+		want         float64
+		wantCategory string
+	}{
+		{
+			description:  "mild",
+			conditions:   weather.Conditions{Temperature: float64Ptr(20), Humidity: float64Ptr(50), TempUnit: weather.TempUnitCelsius},
+			want:         65.2,
+			wantCategory: "Normal",
+		},
+		{
+			description:  "warm and humid",
+			conditions:   weather.Conditions{Temperature: float64Ptr(27), Humidity: float64Ptr(60), TempUnit: weather.TempUnitCelsius},
+			want:         75.6,
+			wantCategory: "Alert",
+		},
+		{
+			description:  "hot in Fahrenheit",
+			conditions:   weather.Conditions{Temperature: float64Ptr(86), Humidity: float64Ptr(80), TempUnit: weather.TempUnitFahrenheit},
+			want:         82.6,
+			wantCategory: "Danger",
+		},
+		{
+			description:  "very hot and humid",
+			conditions:   weather.Conditions{Temperature: float64Ptr(35), Humidity: float64Ptr(70), TempUnit: weather.TempUnitCelsius},
+			want:         88.8,
+			wantCategory: "Emergency",
+		},
+	}
+
+	for _, tc := range testCases {
+		got := tc.conditions.THI()
+		if tc.want != roundTenth(got) {
+			t.Errorf("Want %v, got %v, testing %v", tc.want, got, tc.description)
+		}
+		gotCategory := tc.conditions.THICategory()
+		if tc.wantCategory != gotCategory {
+			t.Errorf("Want %q, got %q, testing %v", tc.wantCategory, gotCategory, tc.description)
+		}
+	}
+
+	noHumidity := weather.Conditions{Temperature: float64Ptr(20), TempUnit: weather.TempUnitCelsius}
+	if got := noHumidity.THI(); !math.IsNaN(got) {
+		t.Errorf("Want NaN without humidity, got %v", got)
+	}
+	if got := noHumidity.THICategory(); got != "" {
+		t.Errorf("Want no category without humidity, got %q", got)
+	}
+}
+
+func TestToSlack(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		conditions  weather.Conditions
+		location    string
+		want        string
+	}{
+		{
+			description: "all fields",
+			conditions:  greatNeckConditions(t),
+			location:    "Great Neck Plaza,NY,US",
+			want:        `{"blocks":[{"type":"header","text":{"type":"plain_text","text":"Great Neck Plaza,NY,US"}},{"type":"divider"},{"type":"section","fields":[{"type":"mrkdwn","text":"*Temperature*\n55.4 ºF"},{"type":"mrkdwn","text":"*Humidity*\n92.0%"},{"type":"mrkdwn","text":"*Wind*\n5.6 mph"},{"type":"mrkdwn","text":"*Description*\novercast clouds"}]}]}`,
+		},
+		{
+			description: "escaped description",
+			conditions:  weather.Conditions{Description: stringPtr("sand & dust <whirls>")},
+			location:    `"Home"`,
+			want:        `{"blocks":[{"type":"header","text":{"type":"plain_text","text":"\"Home\""}},{"type":"divider"},{"type":"section","fields":[{"type":"mrkdwn","text":"*Description*\nsand &amp; dust &lt;whirls&gt;"}]}]}`,
+		},
+		{
+			description: "no fields",
+			conditions:  weather.Conditions{},
+			location:    "London",
+			want:        `{"blocks":[{"type":"header","text":{"type":"plain_text","text":"London"}},{"type":"divider"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		got := tc.conditions.ToSlack(tc.location)
+		if tc.want != got {
+			t.Errorf("Want %s, got %s, testing %v", tc.want, got, tc.description)
+		}
+		if !json.Valid([]byte(got)) {
+			t.Errorf("Want valid JSON, got %s, testing %v", got, tc.description)
+		}
+	}
+}
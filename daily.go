@@ -0,0 +1,241 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// dailyForecastURI is the OpenWeatherMap.org 16 day daily forecast API, which
+// requires a paid subscription.
+const dailyForecastURI = "/data/2.5/forecast/daily"
+
+// maxDailyForecastDays is the most days returned by the daily forecast API.
+const maxDailyForecastDays = 16
+
+// DailyConditions stores API-agnostic weather conditions for one day.
+// Temperatures are in the unit of TempUnit. Fields which were not supplied by
+// the weather API are nil.
+type DailyConditions struct {
+	// Date is the time being forecast, in the time zone of the location.
+	Date        time.Time `json:"date"`
+	Description *string   `json:"description,omitempty"`
+	TempMin     *float64  `json:"temp_min,omitempty"`
+	TempMax     *float64  `json:"temp_max,omitempty"`
+	// Humidity is the mean humidity of the day, in percent.
+	Humidity *float64 `json:"humidity,omitempty"`
+	// PrecipitationProbability is the chance of precipitation, in percent.
+	PrecipitationProbability *float64 `json:"precipitation_probability,omitempty"`
+	TempUnit                 TempUnit `json:"temp_unit"`
+	// IsStale is true when the weather API returned an error, and these are
+	// the last conditions successfully received. See WithStaleIfError.
+	IsStale bool `json:"is_stale,omitempty"`
+}
+
+// owmDailyResponse stores fields from the OpenWeatherMap.org API
+// `/2.5/forecast/daily`. This does not fully mirror the API!
+type owmDailyResponse struct {
+	Cod     owmString
+	Message owmString
+	List    []struct {
+		Dt   int64
+		Temp struct {
+			Min, Max owmFloat
+		}
+		Humidity owmFloat
+		Pop      owmFloat
+		Weather  []struct {
+			Description *string
+		}
+	}
+	City struct {
+		Timezone int
+	}
+}
+
+// LongRangeForecast accepts a location and returns daily conditions for up to
+// 16 days, in the temperature unit set in the weather client. This uses the
+// daily forecast API, which requires a paid OpenWeatherMap.org subscription.
+func (c *Client) LongRangeForecast(location string, days int) ([]DailyConditions, error) {
+	if days < 1 || days > maxDailyForecastDays {
+		return nil, fmt.Errorf("forecast days %d is out of range, please specify from 1 to %d days", days, maxDailyForecastDays)
+	}
+
+	var forecast []DailyConditions
+	stale, err := c.query(context.Background(), c.formEndpointURL(dailyForecastURI, "q", c.resolveLocation(location), days), func(data []byte) error {
+		var err error
+		forecast, err = c.parseDailyForecast(data)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error querying weather API for daily forecast for location %q: %w", location, err)
+	}
+	for i := range forecast {
+		forecast[i].IsStale = stale
+	}
+	return forecast, nil
+}
+
+// parseDailyForecast accepts JSON from the OpenWeatherMap.org API
+// `/2.5/forecast/daily`, and returns daily conditions in the temperature unit
+// set in the weather client. Unknown fields are an error if the weather client
+// disallows them.
+func (c *Client) parseDailyForecast(data []byte) ([]DailyConditions, error) {
+	var ar owmDailyResponse
+	err := c.decodeJSON(data, &ar)
+	if err != nil {
+		return nil, err
+	}
+	err = checkCod(ar.Cod, ar.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ar.List) == 0 {
+		return nil, fmt.Errorf("unexpected empty `List` from weather API: %+v", ar)
+	}
+
+	convertTemp := func(f owmFloat) *float64 {
+		if !f.present {
+			return nil
+		}
+		t := tempFromKelvin(f.value, c.tempUnit)
+		return &t
+	}
+
+	forecast := make([]DailyConditions, len(ar.List))
+	for i, entry := range ar.List {
+		d := DailyConditions{
+			Date:                     time.Unix(entry.Dt, 0).In(zone(ar.City.Timezone)),
+			TempMin:                  convertTemp(entry.Temp.Min),
+			TempMax:                  convertTemp(entry.Temp.Max),
+			Humidity:                 entry.Humidity.store(new(float64)),
+			PrecipitationProbability: entry.Pop.store(new(float64)),
+			TempUnit:                 c.tempUnit,
+		}
+		if len(entry.Weather) > 0 && entry.Weather[0].Description != nil {
+			description := c.truncateDescription(*entry.Weather[0].Description)
+			d.Description = &description
+		}
+		// The weather API returns probability as a fraction.
+		if d.PrecipitationProbability != nil {
+			*d.PrecipitationProbability *= 100
+		}
+		err = c.checkHumidity(Conditions{Humidity: d.Humidity})
+		if err != nil {
+			return nil, err
+		}
+		forecast[i] = d
+	}
+	return forecast, nil
+}
+
+// DailyForecast accepts a location and returns conditions for each day of the
+// next five days, combined from the three hour forecast entries of the weather
+// API, in the temperature unit set in the weather client. Unlike
+// LongRangeForecast, this needs no paid subscription. Days are in the time
+// zone of the location, and the partial first and last days of the forecast
+// are included. TempMin and TempMax are the lowest and highest temperatures of
+// the day's entries, Humidity and PrecipitationProbability are their averages,
+// and Description is their most common description.
+func (c *Client) DailyForecast(location string) ([]DailyConditions, error) {
+	forecast, err := c.HourlyForecast(location, maxForecastCount)
+	if err != nil {
+		return nil, err
+	}
+	return dailyFromHourly(forecast, c.tempUnit), nil
+}
+
+// dailyFromHourly combines forecast entries into daily conditions, in the
+// order of the days of the entries.
+func dailyFromHourly(forecast []Conditions, tempUnit TempUnit) []DailyConditions {
+	// dayTotals accumulates the entries of one day.
+	type dayTotals struct {
+		daily                   DailyConditions
+		humiditySum, popSum     float64
+		humidityCount, popCount int
+		descriptionCounts       map[string]int
+	}
+	var days []*dayTotals
+	byDate := make(map[time.Time]*dayTotals)
+	for _, w := range forecast {
+		y, m, d := w.Time.Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, w.Time.Location())
+		day, ok := byDate[date]
+		if !ok {
+			day = &dayTotals{
+				daily:             DailyConditions{Date: date, TempUnit: tempUnit},
+				descriptionCounts: make(map[string]int),
+			}
+			byDate[date] = day
+			days = append(days, day)
+		}
+
+		if w.Temperature != nil {
+			t := *w.Temperature
+			if day.daily.TempMin == nil || t < *day.daily.TempMin {
+				min := t
+				day.daily.TempMin = &min
+			}
+			if day.daily.TempMax == nil || t > *day.daily.TempMax {
+				max := t
+				day.daily.TempMax = &max
+			}
+		}
+		if w.Humidity != nil {
+			day.humiditySum += *w.Humidity
+			day.humidityCount++
+		}
+		if w.PrecipitationProbability != nil {
+			day.popSum += *w.PrecipitationProbability
+			day.popCount++
+		}
+		day.daily.IsStale = day.daily.IsStale || w.IsStale
+		if w.Description != nil {
+			day.descriptionCounts[*w.Description]++
+			// The earliest description wins a tie.
+			if day.daily.Description == nil || day.descriptionCounts[*w.Description] > day.descriptionCounts[*day.daily.Description] {
+				description := *w.Description
+				day.daily.Description = &description
+			}
+		}
+	}
+
+	daily := make([]DailyConditions, len(days))
+	for i, day := range days {
+		if day.humidityCount > 0 {
+			humidity := day.humiditySum / float64(day.humidityCount)
+			day.daily.Humidity = &humidity
+		}
+		if day.popCount > 0 {
+			pop := day.popSum / float64(day.popCount)
+			day.daily.PrecipitationProbability = &pop
+		}
+		daily[i] = day.daily
+	}
+	return daily
+}
+
+// Thresholds for RainLikelihood, as a chance of precipitation in percent.
+const (
+	rainPossiblePercent = 20.0
+	rainLikelyPercent   = 60.0
+)
+
+// RainLikelihood describes the chance of precipitation for the day as
+// "Unlikely" below 20%, "Possible" from 20% to 60%, or "Likely" above 60%. For
+// DailyForecast, this is the average chance across the day's forecast entries.
+// An empty string is returned if the weather API did not supply a chance of
+// precipitation.
+func (d DailyConditions) RainLikelihood() string {
+	switch p := d.PrecipitationProbability; {
+	case p == nil:
+		return ""
+	case *p < rainPossiblePercent:
+		return "Unlikely"
+	case *p <= rainLikelyPercent:
+		return "Possible"
+	default:
+		return "Likely"
+	}
+}
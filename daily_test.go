@@ -0,0 +1,194 @@
+package weather_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"weather"
+)
+
+func TestLongRangeForecast(t *testing.T) {
+	t.Parallel()
+
+	data, err := ioutil.ReadFile("testdata/daily.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const wantRequestURL = "/data/2.5/forecast/daily/?q=Great+Neck+Plaza%2CNY%2CUS&appid=0123456789abcdef0123456789abcdef&cnt=3"
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestURL := r.URL.String()
+		if wantRequestURL != gotRequestURL {
+			t.Errorf("Want %q, got %q comparing API URI", wantRequestURL, gotRequestURL)
+		}
+		_, err := w.Write(data)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithTempUnit(weather.TempUnitCelsius),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.LongRangeForecast("Great Neck Plaza,NY,US", 3)
+	if err != nil {
+		t.Fatalf("Error while getting long range forecast: %v", err)
+	}
+
+	want := []struct {
+		date                     string
+		description              string
+		tempMin, tempMax         float64
+		humidity                 float64
+		precipitationProbability float64
+	}{
+		{date: "2021-04-11T14:00:00-04:00", description: "light rain", tempMin: 10, tempMax: 17.2, humidity: 71, precipitationProbability: 65},
+		{date: "2021-04-12T14:00:00-04:00", description: "broken clouds", tempMin: 11.1, tempMax: 20.6, humidity: 60, precipitationProbability: 10},
+		{date: "2021-04-13T14:00:00-04:00", description: "clear sky", tempMin: 12.8, tempMax: 22.2, humidity: 55, precipitationProbability: 0},
+	}
+	if len(want) != len(got) {
+		t.Fatalf("Want %d days, got %d", len(want), len(got))
+	}
+	for i, w := range want {
+		d := got[i]
+		if w.date != d.Date.Format(time.RFC3339) {
+			t.Errorf("Want date %s, got %s", w.date, d.Date.Format(time.RFC3339))
+		}
+		if d.Description == nil || w.description != *d.Description {
+			t.Errorf("Want description %q, got %v", w.description, d.Description)
+		}
+		if d.TempMin == nil || w.tempMin != roundTenth(*d.TempMin) {
+			t.Errorf("Want minimum temperature %v, got %v", w.tempMin, d.TempMin)
+		}
+		if d.TempMax == nil || w.tempMax != roundTenth(*d.TempMax) {
+			t.Errorf("Want maximum temperature %v, got %v", w.tempMax, d.TempMax)
+		}
+		if d.Humidity == nil || w.humidity != *d.Humidity {
+			t.Errorf("Want humidity %v, got %v", w.humidity, d.Humidity)
+		}
+		if d.PrecipitationProbability == nil || w.precipitationProbability != roundTenth(*d.PrecipitationProbability) {
+			t.Errorf("Want precipitation probability %v, got %v", w.precipitationProbability, d.PrecipitationProbability)
+		}
+		if d.TempUnit != weather.TempUnitCelsius {
+			t.Errorf("Want temperature unit %v, got %v", weather.TempUnitCelsius, d.TempUnit)
+		}
+	}
+
+	_, err = wc.LongRangeForecast("Great Neck Plaza,NY,US", 17)
+	if err == nil {
+		t.Error("Want an error for more than 16 days, got nil")
+	}
+}
+
+func TestRainLikelihood(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		probability *float64
+		want        string
+	}{
+		{description: "no chance", probability: float64Ptr(0), want: "Unlikely"},
+		{description: "just below possible", probability: float64Ptr(19.9), want: "Unlikely"},
+		{description: "lower bound of possible", probability: float64Ptr(20), want: "Possible"},
+		{description: "upper bound of possible", probability: float64Ptr(60), want: "Possible"},
+		{description: "just above possible", probability: float64Ptr(60.1), want: "Likely"},
+		{description: "certain", probability: float64Ptr(100), want: "Likely"},
+		{description: "not supplied", probability: nil, want: ""},
+	}
+
+	for _, tc := range testCases {
+		d := weather.DailyConditions{PrecipitationProbability: tc.probability}
+		got := d.RainLikelihood()
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestDailyForecast(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(t, "testdata/greatneck_8slots.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithTempUnit(weather.TempUnitCelsius),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.DailyForecast("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting daily forecast: %v", err)
+	}
+
+	// The first entry is late on April 10 in the time zone of the location,
+	// and the other seven are on April 11.
+	zone := time.FixedZone("", -4*60*60)
+	testCases := []struct {
+		date                       time.Time
+		description                string
+		tempMin, tempMax, humidity float64
+		probability                float64
+		wantLikelihood             string
+	}{
+		{
+			date:           time.Date(2021, time.April, 10, 0, 0, 0, 0, zone),
+			description:    "overcast clouds",
+			tempMin:        12.9,
+			tempMax:        12.9,
+			humidity:       92,
+			probability:    0,
+			wantLikelihood: "Unlikely",
+		},
+		{
+			date:           time.Date(2021, time.April, 11, 0, 0, 0, 0, zone),
+			description:    "light rain",
+			tempMin:        11.2,
+			tempMax:        17.1,
+			humidity:       77.6,
+			probability:    24.3,
+			wantLikelihood: "Possible",
+		},
+	}
+	if len(testCases) != len(got) {
+		t.Fatalf("Want %d days, got %d: %+v", len(testCases), len(got), got)
+	}
+	for i, tc := range testCases {
+		d := got[i]
+		if !tc.date.Equal(d.Date) {
+			t.Errorf("Want date %v, got %v, testing day %d", tc.date, d.Date, i)
+		}
+		if d.Description == nil || tc.description != *d.Description {
+			t.Errorf("Want description %q, got %v, testing day %d", tc.description, d.Description, i)
+		}
+		for _, f := range []struct {
+			name string
+			want float64
+			got  *float64
+		}{
+			{name: "minimum temperature", want: tc.tempMin, got: d.TempMin},
+			{name: "maximum temperature", want: tc.tempMax, got: d.TempMax},
+			{name: "humidity", want: tc.humidity, got: d.Humidity},
+			{name: "precipitation probability", want: tc.probability, got: d.PrecipitationProbability},
+		} {
+			if f.got == nil || f.want != roundTenth(*f.got) {
+				t.Errorf("Want %s %v, got %v, testing day %d", f.name, f.want, f.got, i)
+			}
+		}
+		if tc.wantLikelihood != d.RainLikelihood() {
+			t.Errorf("Want rain likelihood %q, got %q, testing day %d", tc.wantLikelihood, d.RainLikelihood(), i)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package weather
+
+import (
+	"math"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// conditionsDiff stores the significant changes between two sets of weather
+// conditions. Numeric changes are the later value less the earlier one, and
+// are nil when there is no significant change or a value is not present.
+type conditionsDiff struct {
+	Temperature, Humidity, WindSpeed *float64
+	// OldDescription and NewDescription are only set when the description
+	// changed.
+	OldDescription, NewDescription *string
+	TempUnit                       TempUnit
+	SpeedUnit                      SpeedUnit
+}
+
+// diffThreshold is the smallest numeric change which Diff considers
+// significant, as numbers are formatted to one decimal place.
+const diffThreshold = 0.05
+
+// diffTemplate describes a conditionsDiff in prose, with changes separated
+// by "; ".
+var diffTemplate = template.Must(template.New("diff").Funcs(template.FuncMap{
+	"direction": func(f float64) string {
+		if f < 0 {
+			return "fell"
+		}
+		return "rose"
+	},
+	"abs":       math.Abs,
+	"tempUnit":  func(u TempUnit) string { return tempUnitName[u] },
+	"speedUnit": func(u SpeedUnit) string { return speedUnitName[u] },
+}).Parse(
+	`{{with .Temperature}}temperature {{direction .}} {{printf "%.1f" (abs .)}}{{tempUnit $.TempUnit}}; {{end}}` +
+		`{{with .Humidity}}humidity {{direction .}} {{printf "%.1f" (abs .)}}%; {{end}}` +
+		`{{with .WindSpeed}}wind speed {{direction .}} {{printf "%.1f" (abs .)}} {{speedUnit $.SpeedUnit}}; {{end}}` +
+		`{{if .NewDescription}}description changed from '{{.OldDescription}}' to '{{.NewDescription}}'; {{end}}`))
+
+// diff returns the significant changes from weather conditions w to other,
+// in the units of w.
+func (w Conditions) diff(other Conditions) conditionsDiff {
+	other = other.convert(w.TempUnit, w.SpeedUnit)
+	change := func(a, b *float64) *float64 {
+		if a == nil || b == nil || math.Abs(*b-*a) < diffThreshold {
+			return nil
+		}
+		c := *b - *a
+		return &c
+	}
+
+	d := conditionsDiff{
+		Temperature: change(w.Temperature, other.Temperature),
+		Humidity:    change(w.Humidity, other.Humidity),
+		WindSpeed:   change(w.WindSpeed, other.WindSpeed),
+		TempUnit:    w.TempUnit,
+		SpeedUnit:   w.SpeedUnit,
+	}
+	if w.Description != nil && other.Description != nil && *w.Description != *other.Description {
+		d.OldDescription, d.NewDescription = w.Description, other.Description
+	}
+	return d
+}
+
+// Diff returns a summary of the significant changes from weather conditions
+// w to other, such as "Temperature fell 3.2 ºF; description changed from
+// 'clear sky' to 'overcast clouds'," or "No significant change." Temperature,
+// humidity, wind speed, and description are compared, other is converted to
+// the units of w, and a field which is not present in both is ignored.
+func (w Conditions) Diff(other Conditions) string {
+	var b strings.Builder
+	// The template only uses fields and functions which do not fail.
+	_ = diffTemplate.Execute(&b, w.diff(other))
+
+	s := strings.TrimSuffix(b.String(), "; ")
+	if s == "" {
+		return "No significant change"
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
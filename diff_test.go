@@ -0,0 +1,71 @@
+package weather_test
+
+import (
+	"testing"
+	"weather"
+)
+
+func TestConditionsDiff(t *testing.T) {
+	t.Parallel()
+
+	clear := weather.Conditions{
+		Description: stringPtr("clear sky"),
+		Temperature: float64Ptr(58.6),
+		Humidity:    float64Ptr(80),
+		WindSpeed:   float64Ptr(5.6),
+		TempUnit:    weather.TempUnitFahrenheit,
+		SpeedUnit:   weather.SpeedUnitMiles,
+	}
+
+	testCases := []struct {
+		description string
+		other       weather.Conditions
+		want        string
+	}{
+		{
+			description: "no change",
+			other:       clear,
+			want:        "No significant change",
+		},
+		{
+			description: "temperature and description",
+			other: weather.Conditions{
+				Description: stringPtr("overcast clouds"),
+				Temperature: float64Ptr(55.4),
+				Humidity:    float64Ptr(80.01),
+				WindSpeed:   float64Ptr(5.6),
+				TempUnit:    weather.TempUnitFahrenheit,
+				SpeedUnit:   weather.SpeedUnitMiles,
+			},
+			want: "Temperature fell 3.2 ºF; description changed from 'clear sky' to 'overcast clouds'",
+		},
+		{
+			description: "humidity and wind in other units",
+			other: weather.Conditions{
+				Description: stringPtr("clear sky"),
+				Temperature: float64Ptr(14.63),
+				Humidity:    float64Ptr(92),
+				WindSpeed:   float64Ptr(1.5),
+				TempUnit:    weather.TempUnitCelsius,
+				SpeedUnit:   weather.SpeedUnitMeters,
+			},
+			want: "Humidity rose 12.0%; wind speed fell 2.2 mph",
+		},
+		{
+			description: "fields missing from other are ignored",
+			other: weather.Conditions{
+				Temperature: float64Ptr(60.1),
+				TempUnit:    weather.TempUnitFahrenheit,
+				SpeedUnit:   weather.SpeedUnitMiles,
+			},
+			want: "Temperature rose 1.5 ºF",
+		},
+	}
+
+	for _, tc := range testCases {
+		got := clear.Diff(tc.other)
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrLocationNotFound is returned when the weather API does not recognize a
+// location.
+var ErrLocationNotFound = errors.New("location not found")
+
+// ErrNoWeatherData is returned when formatting weather conditions which have
+// no usable fields, if the weather client formats strictly. See
+// WithStrictFormatting.
+var ErrNoWeatherData = errors.New("no weather data available")
+
+// ErrInvalidAPIKey is returned when the weather API does not accept the API
+// key, with a message explaining how to get a valid key. Use errors.As to get
+// the APIError from the weather API.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// OWMErrorBody stores the body of an error response from the weather API,
+// such as {"cod": "404", "message": "city not found"}.
+type OWMErrorBody struct {
+	// Cod is the error code, which the weather API returns as a string or a
+	// number. It is 0 if the code is not a number.
+	Cod     int
+	Message string
+}
+
+// APIError is returned when the weather API responds with an error, either
+// an unsuccessful HTTP status, or an error code in the body of a response
+// with a successful status.
+type APIError struct {
+	StatusCode int
+	Status     string
+	// Body is nil if the response body is not an error from the weather API.
+	Body *OWMErrorBody
+	// raw is the response body.
+	raw string
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == http.StatusOK && e.Body != nil {
+		return fmt.Sprintf("weather API returned code %d: %s", e.Body.Cod, e.Body.Message)
+	}
+	// Including the HTTP body can help by providing a message from the weather API.
+	return "HTTP " + e.Status + " returned from weather API: " + e.raw
+}
+
+// newAPIError returns an APIError for an unsuccessful weather API response,
+// parsing the body if it is an error from the weather API.
+func newAPIError(statusCode int, status string, data []byte) *APIError {
+	e := &APIError{StatusCode: statusCode, Status: status, raw: string(data)}
+	var body struct {
+		Cod     owmString
+		Message owmString
+	}
+	if json.Unmarshal(data, &body) == nil && (body.Cod != "" || body.Message != "") {
+		e.Body = newOWMErrorBody(body.Cod, body.Message)
+	}
+	return e
+}
+
+// checkAPIKeyError returns an error wrapping ErrInvalidAPIKey and e, if the
+// weather API did not accept the API key, and otherwise returns e.
+func checkAPIKeyError(e *APIError) error {
+	if e.StatusCode != http.StatusUnauthorized && (e.Body == nil || e.Body.Cod != http.StatusUnauthorized) {
+		return e
+	}
+	return fmt.Errorf("%w, please check the OPENWEATHERMAP_API_KEY or get a valid key from https://home.openweathermap.org/api_keys: %w", ErrInvalidAPIKey, e)
+}
+
+// newOWMErrorBody returns an OWMErrorBody for the `cod` and `message` of a
+// weather API response.
+func newOWMErrorBody(cod, message owmString) *OWMErrorBody {
+	// A code which is not a number is left as 0.
+	n, _ := strconv.Atoi(string(cod))
+	return &OWMErrorBody{Cod: n, Message: string(message)}
+}
+
+// MultiError is returned by batch operations, such as BatchForecast, when
+// some of the operations failed. It lists the error of each failure, and
+// errors.Is and errors.As match any of them.
+type MultiError struct {
+	Errs []error
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error of each failure.
+func (e *MultiError) Unwrap() []error {
+	return e.Errs
+}
+
+// SubscriptionRequiredError is returned when the API key is not subscribed to
+// a weather API endpoint which requires a paid plan, such as the One Call API
+// for free API keys.
+type SubscriptionRequiredError struct {
+	Endpoint string
+}
+
+func (e *SubscriptionRequiredError) Error() string {
+	return "the weather API endpoint " + e.Endpoint + " requires a paid OpenWeatherMap.org subscription, see https://openweathermap.org/price"
+}
+
+// ContextCanceledError is returned when a weather API request is stopped
+// because its context was cancelled.
+type ContextCanceledError struct {
+	Err error
+}
+
+func (e *ContextCanceledError) Error() string {
+	return "weather API request cancelled: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error, which matches context.Canceled.
+func (e *ContextCanceledError) Unwrap() error {
+	return e.Err
+}
+
+// DeadlineExceededError is returned when a weather API request is stopped
+// because the deadline of its context passed.
+type DeadlineExceededError struct {
+	Err error
+}
+
+func (e *DeadlineExceededError) Error() string {
+	return "weather API request exceeded its context deadline: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error, which matches
+// context.DeadlineExceeded.
+func (e *DeadlineExceededError) Unwrap() error {
+	return e.Err
+}
+
+// ClientTimeoutError is returned when a weather API request is stopped by the
+// timeout of the HTTP client, such as the default three second timeout, as
+// opposed to a deadline of its context.
+type ClientTimeoutError struct {
+	Err error
+}
+
+func (e *ClientTimeoutError) Error() string {
+	return "weather API request exceeded the HTTP client timeout: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ClientTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// classifyRequestError wraps an error from an HTTP request as a
+// ContextCanceledError, DeadlineExceededError, or ClientTimeoutError where it
+// is one of those, and otherwise returns it unchanged. The context is
+// checked, because the HTTP client can also report its own timeout as
+// context.DeadlineExceeded.
+func classifyRequestError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return &ContextCanceledError{Err: err}
+	case errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &DeadlineExceededError{Err: err}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &ClientTimeoutError{Err: err}
+	}
+	return err
+}
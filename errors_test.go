@@ -0,0 +1,276 @@
+package weather_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"weather"
+)
+
+// newSlowTestServer returns a test HTTP server which does not respond until
+// the request is cancelled, to trigger timeouts.
+func newSlowTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestForecastContextErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := newSlowTestServer(t)
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		wc, err := weather.NewClient(testAPIKey, weather.WithAPIHost(ts.URL))
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = wc.ForecastWithContext(ctx, "London")
+		var want *weather.ContextCanceledError
+		if !errors.As(err, &want) {
+			t.Errorf("Want a ContextCanceledError, got %T: %v", err, err)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Want an error matching context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		wc, err := weather.NewClient(testAPIKey, weather.WithAPIHost(ts.URL))
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = wc.ForecastWithContext(ctx, "London")
+		var want *weather.DeadlineExceededError
+		if !errors.As(err, &want) {
+			t.Errorf("Want a DeadlineExceededError, got %T: %v", err, err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Want an error matching context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("client timeout", func(t *testing.T) {
+		t.Parallel()
+
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		_, err = wc.ForecastWithContext(context.Background(), "London")
+		var want *weather.ClientTimeoutError
+		if !errors.As(err, &want) {
+			t.Errorf("Want a ClientTimeoutError, got %T: %v", err, err)
+		}
+		var wrong *weather.DeadlineExceededError
+		if errors.As(err, &wrong) {
+			t.Errorf("Want a client timeout to not be a DeadlineExceededError, got %v", err)
+		}
+	})
+}
+
+func TestForecastLocationNotFound(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		body        string
+		wantErr     error
+		want        string
+	}{
+		{
+			description: "string code 404",
+			body:        `{"cod": "404", "message": "city not found"}`,
+			wantErr:     weather.ErrLocationNotFound,
+			want:        "location not found: city not found",
+		},
+		{
+			description: "numeric code 401",
+			body:        `{"cod": 401, "message": "Invalid API key."}`,
+			want:        "weather API returned code 401: Invalid API key.",
+		},
+	}
+
+	for _, tc := range testCases {
+		ts := newTestServerWithBody(t, []byte(tc.body))
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		_, err = wc.Forecast("Nowhere")
+		if err == nil {
+			t.Fatalf("Want an error, got nil, testing %v", tc.description)
+		}
+		if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+			t.Errorf("Want error matching %v, got %v, testing %v", tc.wantErr, err, tc.description)
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Want an error containing %q, got %v, testing %v", tc.want, err, tc.description)
+		}
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description    string
+		status         int
+		body           string
+		wantStatusCode int
+		wantBody       *weather.OWMErrorBody
+	}{
+		{
+			description:    "HTTP 404 with a string code",
+			status:         http.StatusNotFound,
+			body:           `{"cod": "404", "message": "city not found"}`,
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       &weather.OWMErrorBody{Cod: 404, Message: "city not found"},
+		},
+		{
+			description:    "HTTP 401 with a numeric code",
+			status:         http.StatusUnauthorized,
+			body:           `{"cod": 401, "message": "Invalid API key. Please see https://openweathermap.org/faq#error401 for more info."}`,
+			wantStatusCode: http.StatusUnauthorized,
+			wantBody:       &weather.OWMErrorBody{Cod: 401, Message: "Invalid API key. Please see https://openweathermap.org/faq#error401 for more info."},
+		},
+		{
+			description:    "HTTP 200 with an error code",
+			status:         http.StatusOK,
+			body:           `{"cod": 401, "message": "Invalid API key."}`,
+			wantStatusCode: http.StatusOK,
+			wantBody:       &weather.OWMErrorBody{Cod: 401, Message: "Invalid API key."},
+		},
+		{
+			description:    "HTTP 502 without an error body",
+			status:         http.StatusBadGateway,
+			body:           `<html>Bad Gateway</html>`,
+			wantStatusCode: http.StatusBadGateway,
+		},
+	}
+
+	for _, tc := range testCases {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			_, err := w.Write([]byte(tc.body))
+			if err != nil {
+				t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+			}
+		}))
+		t.Cleanup(ts.Close)
+
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		_, err = wc.Forecast("Nowhere")
+		var apiErr *weather.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Want an APIError, got %T: %v, testing %v", err, err, tc.description)
+		}
+		if tc.wantStatusCode != apiErr.StatusCode {
+			t.Errorf("Want status code %d, got %d, testing %v", tc.wantStatusCode, apiErr.StatusCode, tc.description)
+		}
+		if !reflect.DeepEqual(tc.wantBody, apiErr.Body) {
+			t.Errorf("Want body %+v, got %+v, testing %v", tc.wantBody, apiErr.Body, tc.description)
+		}
+	}
+}
+
+func TestForecastInvalidAPIKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		status      int
+		body        string
+		want        bool
+	}{
+		{
+			description: "HTTP 401",
+			status:      http.StatusUnauthorized,
+			body:        `{"cod": 401, "message": "Invalid API key. Please see https://openweathermap.org/faq#error401 for more info."}`,
+			want:        true,
+		},
+		{
+			description: "HTTP 200 with code 401",
+			status:      http.StatusOK,
+			body:        `{"cod": "401", "message": "Invalid API key."}`,
+			want:        true,
+		},
+		{
+			description: "HTTP 404",
+			status:      http.StatusNotFound,
+			body:        `{"cod": "404", "message": "city not found"}`,
+			want:        false,
+		},
+	}
+
+	for _, tc := range testCases {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			_, err := w.Write([]byte(tc.body))
+			if err != nil {
+				t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+			}
+		}))
+		t.Cleanup(ts.Close)
+
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		_, err = wc.Forecast("London")
+		if got := errors.Is(err, weather.ErrInvalidAPIKey); tc.want != got {
+			t.Errorf("Want ErrInvalidAPIKey %v, got %v: %v, testing %v", tc.want, got, err, tc.description)
+		}
+		if !tc.want {
+			continue
+		}
+		if !strings.Contains(err.Error(), "https://home.openweathermap.org/api_keys") {
+			t.Errorf("Want an error with where to get an API key, got %v, testing %v", err, tc.description)
+		}
+		var apiErr *weather.APIError
+		if !errors.As(err, &apiErr) || apiErr.Body == nil || apiErr.Body.Cod != 401 {
+			t.Errorf("Want an APIError with code 401, got %v, testing %v", err, tc.description)
+		}
+	}
+}
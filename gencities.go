@@ -0,0 +1,147 @@
+//go:build ignore
+
+// gencities generates cities.csv, which maps the OpenWeatherMap.org IDs of
+// well-known cities to names for display. Cities are listed in cities.txt, one
+// per line, as "Name,CountryCode" or "Name,StateCode,CountryCode" where the
+// name is ambiguous within a country, and are looked up in the
+// OpenWeatherMap.org city list, city.list.json.gz, downloaded from
+// https://bulk.openweathermap.org/sample/
+//
+// Run this using `go generate` with city.list.json.gz in the current
+// directory. A city which is not found, or which matches more than one entry
+// of the city list, is reported and left out.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// owmCity stores fields of an entry of the OpenWeatherMap.org city list.
+type owmCity struct {
+	ID      int
+	Name    string
+	State   string
+	Country string
+}
+
+func main() {
+	listFile := flag.String("list", "city.list.json.gz", "The OpenWeatherMap.org city list.")
+	namesFile := flag.String("names", "cities.txt", "The cities to include, one per line.")
+	outFile := flag.String("o", "cities.csv", "The CSV file to write.")
+	flag.Parse()
+
+	err := run(*listFile, *namesFile, *outFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(listFile, namesFile, outFile string) error {
+	cities, err := readCityList(listFile)
+	if err != nil {
+		return err
+	}
+	names, err := readNames(namesFile)
+	if err != nil {
+		return err
+	}
+
+	// Index the city list by name and country, to find each city quickly.
+	byName := make(map[string][]owmCity)
+	for _, c := range cities {
+		key := c.Name + "," + c.Country
+		byName[key] = append(byName[key], c)
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, "# Code generated by gencities.go from the OpenWeatherMap.org city list; DO NOT EDIT.")
+	w := csv.NewWriter(f)
+	err = w.Write([]string{"id", "name"})
+	if err != nil {
+		return err
+	}
+
+	for _, parts := range names {
+		name, state, country := parts[0], "", parts[len(parts)-1]
+		if len(parts) == 3 {
+			state = parts[1]
+		}
+
+		var matches []owmCity
+		for _, c := range byName[name+","+country] {
+			if state == "" || c.State == state {
+				matches = append(matches, c)
+			}
+		}
+		if len(matches) != 1 {
+			fmt.Fprintf(os.Stderr, "skipping %q, which matches %d cities in the city list\n", strings.Join(parts, ","), len(matches))
+			continue
+		}
+
+		err = w.Write([]string{strconv.Itoa(matches[0].ID), strings.Join(parts, ", ")})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// readCityList returns the entries of a gzipped OpenWeatherMap.org city list.
+func readCityList(file string) ([]owmCity, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading city list %s: %w", file, err)
+	}
+	var cities []owmCity
+	err = json.NewDecoder(gz).Decode(&cities)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading city list %s: %w", file, err)
+	}
+	return cities, nil
+}
+
+// readNames returns the comma-separated parts of each city listed in a file,
+// ignoring blank lines and lines beginning with #.
+func readNames(file string) ([][]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var names [][]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, ",")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("city %q in %s should be Name,CountryCode or Name,StateCode,CountryCode", line, file)
+		}
+		names = append(names, parts)
+	}
+	return names, scanner.Err()
+}
@@ -1,4 +1,5 @@
 module weather
 
-go 1.15
+go 1.21
 
+require gopkg.in/yaml.v3 v3.0.1
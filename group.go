@@ -0,0 +1,248 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// groupURI is the OpenWeatherMap.org API which returns current weather for
+// multiple city IDs.
+const groupURI = "/data/2.5/group"
+
+// maxGroupIDs is the most city IDs the weather API accepts in one group
+// request.
+const maxGroupIDs = 20
+
+// owmGroupResponse stores fields from the OpenWeatherMap.org APIs
+// `/2.5/group` and `/2.5/box/city`, which both return current weather for
+// multiple cities. This does not fully mirror the API!
+type owmGroupResponse struct {
+	Cod     owmString
+	Message owmString
+	Cnt     int
+	List    []struct {
+		ID      int
+		Dt      int64
+		Weather []owmWeather
+		Main    struct {
+			Temp, Feels_like, Humidity owmFloat
+		}
+		Wind struct {
+			Speed, Deg owmFloat
+		}
+		Coord struct {
+			Lat, Lon owmFloat
+		}
+		Sys struct {
+			Timezone int
+		}
+	}
+}
+
+// formGroupURL returns a group API URL for the specified city IDs.
+func (c Client) formGroupURL(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return fmt.Sprintf("%s%s/?%s=%s&%s=%s", c.APIHost, groupURI, c.paramName("id"), strings.Join(s, ","), c.paramName("appid"), c.APIKey)
+}
+
+// ForecastByCityIDs accepts OpenWeatherMap.org city IDs, and returns
+// current conditions for each city keyed by its ID, in the units set in the
+// weather client. The weather API accepts at most 20 IDs per request, so more
+// IDs are queried using multiple concurrent requests and the results merged.
+// If any request fails, the conditions from successful requests are still
+// returned, along with a MultiError listing the error for each failed
+// request, in the order of IDs. See ForecastByCityID for finding the ID of a
+// city.
+func (c *Client) ForecastByCityIDs(ids []int) (map[int]Conditions, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no city IDs specified")
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		forecast = make(map[int]Conditions, len(ids))
+		errs     = make([]error, (len(ids)+maxGroupIDs-1)/maxGroupIDs)
+	)
+	for start := 0; start < len(ids); start += maxGroupIDs {
+		end := start + maxGroupIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			conditions, err := c.queryGroup(context.Background(), batch)
+			if err != nil {
+				// Each batch has its own error, so this needs no lock.
+				errs[n] = fmt.Errorf("Error querying weather API for city IDs %v: %w", batch, err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for id, w := range conditions {
+				forecast[id] = w
+			}
+		}(start / maxGroupIDs)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return forecast, &MultiError{Errs: failed}
+	}
+	return forecast, nil
+}
+
+// queryGroup accepts at most maxGroupIDs city IDs, and returns current
+// conditions for each city keyed by its ID, in the units set in the weather
+// client.
+func (c *Client) queryGroup(ctx context.Context, ids []int) (map[int]Conditions, error) {
+	var forecast map[int]Conditions
+	stale, err := c.query(ctx, c.formGroupURL(ids), func(data []byte) error {
+		ar, err := c.decodeGroup(data)
+		if err != nil {
+			return err
+		}
+
+		forecast = make(map[int]Conditions, len(ar.List))
+		for i, entry := range ar.List {
+			w, err := ar.conditions(i, c.primaryCondition)
+			if err != nil {
+				return err
+			}
+			err = c.checkHumidity(w)
+			if err != nil {
+				return err
+			}
+			forecast[entry.ID] = w
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for id, w := range forecast {
+		w.IsStale = stale
+		forecast[id] = c.prepareConditions(w)
+	}
+	return forecast, nil
+}
+
+// decodeGroup accepts JSON from the OpenWeatherMap.org APIs `/2.5/group` or
+// `/2.5/box/city`, and returns a weather API response. Unknown fields are an
+// error if the weather client disallows them.
+func (c Client) decodeGroup(data []byte) (owmGroupResponse, error) {
+	var ar owmGroupResponse
+	err := c.decodeJSON(data, &ar)
+	if err != nil {
+		return owmGroupResponse{}, err
+	}
+	err = checkCod(ar.Cod, ar.Message)
+	if err != nil {
+		return owmGroupResponse{}, err
+	}
+	return ar, nil
+}
+
+// conditions returns weather conditions for the `List` entry at index i of a
+// group weather API response, in the Kelvin and meters/sec units used by the
+// weather API. The time of the conditions is in the time zone of the city,
+// and its description is of the condition chosen by primaryCondition, one of
+// the PrimaryCondition... strategies.
+func (ar owmGroupResponse) conditions(i int, primaryCondition string) (Conditions, error) {
+	entry := ar.List[i]
+	if len(entry.Weather) == 0 {
+		return Conditions{}, fmt.Errorf("unexpected empty List[%d].Weather from weather API: %+v", i, ar)
+	}
+
+	primary := entry.Weather[primaryWeather(entry.Weather, primaryCondition)]
+	w := Conditions{
+		Description:      primary.Description,
+		ShortDescription: primary.Main,
+		Temperature:      entry.Main.Temp.store(new(float64)),
+		FeelsLike:        entry.Main.Feels_like.store(new(float64)),
+		Humidity:         entry.Main.Humidity.store(new(float64)),
+		WindSpeed:        entry.Wind.Speed.store(new(float64)),
+		WindDirection:    entry.Wind.Deg.store(new(float64)),
+		Latitude:         entry.Coord.Lat.store(new(float64)),
+		Longitude:        entry.Coord.Lon.store(new(float64)),
+		TempUnit:         TempUnitKelvin,
+		SpeedUnit:        SpeedUnitMeters,
+	}
+	if entry.Dt != 0 {
+		w.Time = time.Unix(entry.Dt, 0).In(zone(entry.Sys.Timezone))
+	}
+	return w, nil
+}
+
+// boxURI is the OpenWeatherMap.org API which returns current weather for
+// cities within a bounding box.
+const boxURI = "/data/2.5/box/city"
+
+// formBoxURL returns a bounding box API URL for the specified coordinates
+// and map zoom level.
+func (c Client) formBoxURL(minLat, minLon, maxLat, maxLon float64, zoom int) string {
+	f := func(n float64) string {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%s%s/?%s=%s,%s,%s,%s,%d&%s=%s", c.APIHost, boxURI, c.paramName("bbox"), f(minLon), f(minLat), f(maxLon), f(maxLat), zoom, c.paramName("appid"), c.APIKey)
+}
+
+// ForecastByBoundingBox accepts the corners of a region and a map zoom level,
+// and returns current conditions for cities within the region, in the units
+// set in the weather client. A higher zoom level returns more, smaller,
+// cities. This is useful for showing weather across a region on a map; the
+// city of each result can be identified by its Latitude and Longitude.
+func (c *Client) ForecastByBoundingBox(minLat, minLon, maxLat, maxLon float64, zoom int) ([]Conditions, error) {
+	if minLat > maxLat || minLon > maxLon {
+		return nil, fmt.Errorf("bounding box %v,%v to %v,%v is invalid, the minimum latitude and longitude must not be greater than the maximum", minLat, minLon, maxLat, maxLon)
+	}
+
+	var forecast []Conditions
+	stale, err := c.query(context.Background(), c.formBoxURL(minLat, minLon, maxLat, maxLon, zoom), func(data []byte) error {
+		ar, err := c.decodeGroup(data)
+		if err != nil {
+			return err
+		}
+
+		forecast = make([]Conditions, len(ar.List))
+		for i := range ar.List {
+			w, err := ar.conditions(i, c.primaryCondition)
+			if err != nil {
+				return err
+			}
+			err = c.checkHumidity(w)
+			if err != nil {
+				return err
+			}
+			forecast[i] = w
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error querying weather API for bounding box %v,%v to %v,%v: %w", minLat, minLon, maxLat, maxLon, err)
+	}
+
+	for i, w := range forecast {
+		w.IsStale = stale
+		forecast[i] = c.prepareConditions(w)
+	}
+	return forecast, nil
+}
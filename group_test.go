@@ -0,0 +1,266 @@
+package weather_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"weather"
+)
+
+func TestForecastByCityIDs(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/data/2.5/group/" {
+			t.Errorf("Want request path %q, got %q", "/data/2.5/group/", r.URL.Path)
+		}
+
+		ids := strings.Split(r.URL.Query().Get("id"), ",")
+		if len(ids) > 20 {
+			t.Errorf("Want at most 20 city IDs per request, got %d", len(ids))
+		}
+
+		// Each city is 286K with its ID as its humidity.
+		entries := make([]string, len(ids))
+		for i, id := range ids {
+			entries[i] = fmt.Sprintf(`{"id": %s, "dt": 1618110000, "weather": [{"main": "Clouds", "description": "overcast clouds"}], "main": {"temp": 286, "humidity": %s}, "wind": {"speed": 2.5}, "sys": {"timezone": -14400}}`, id, id)
+		}
+		fmt.Fprintf(w, `{"cnt": %d, "list": [%s]}`, len(ids), strings.Join(entries, ","))
+	}))
+	t.Cleanup(ts.Close)
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithTempUnit(weather.TempUnitCelsius),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	ids := make([]int, 45)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+
+	got, err := wc.ForecastByCityIDs(ids)
+	if err != nil {
+		t.Fatalf("Error while getting forecast for city IDs: %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("Want 3 requests for 45 city IDs, got %d", requests)
+	}
+	if len(got) != len(ids) {
+		t.Errorf("Want conditions for %d city IDs, got %d", len(ids), len(got))
+	}
+	for _, id := range ids {
+		w, ok := got[id]
+		if !ok {
+			t.Errorf("Want conditions for city ID %d, got none", id)
+			continue
+		}
+		if w.Humidity == nil || *w.Humidity != float64(id) {
+			t.Errorf("Want humidity %d for city ID %d, got %v", id, id, w.Humidity)
+		}
+		if w.Temperature == nil || roundTenth(*w.Temperature) != 12.9 {
+			t.Errorf("Want temperature 12.9 for city ID %d, got %v", id, w.Temperature)
+		}
+	}
+
+	_, err = wc.ForecastByCityIDs(nil)
+	if err == nil {
+		t.Error("Want an error without city IDs, got nil")
+	}
+}
+
+func TestForecastByCityIDsErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := strings.Split(r.URL.Query().Get("id"), ",")
+		switch ids[0] {
+		case "1":
+			w.WriteHeader(http.StatusBadGateway)
+		case "41":
+			// The weather API can report an error despite an HTTP 200 status.
+			fmt.Fprint(w, `{"cod": "404", "message": "city not found"}`)
+		default:
+			entries := make([]string, len(ids))
+			for i, id := range ids {
+				entries[i] = fmt.Sprintf(`{"id": %s, "dt": 1618110000, "weather": [{"main": "Clouds", "description": "overcast clouds"}], "main": {"temp": 286, "humidity": 92}, "wind": {"speed": 2.5}, "sys": {"timezone": -14400}}`, id)
+			}
+			fmt.Fprintf(w, `{"cnt": %d, "list": [%s]}`, len(ids), strings.Join(entries, ","))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	ids := make([]int, 45)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+
+	got, err := wc.ForecastByCityIDs(ids)
+	var multiErr *weather.MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("Want a MultiError, got %v", err)
+	}
+	if len(multiErr.Errs) != 2 {
+		t.Errorf("Want an error for each of the 2 failed requests, got %d: %v", len(multiErr.Errs), err)
+	}
+	if !errors.Is(err, weather.ErrLocationNotFound) {
+		t.Errorf("Want an error matching ErrLocationNotFound, got %v", err)
+	}
+	var apiErr *weather.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Want an APIError with status %d, got %v", http.StatusBadGateway, err)
+	}
+
+	// Conditions from the successful request are still returned.
+	if len(got) != 20 {
+		t.Errorf("Want conditions for the 20 city IDs of the successful request, got %d", len(got))
+	}
+	if _, ok := got[21]; !ok {
+		t.Error("Want conditions for city ID 21, got none")
+	}
+}
+
+func TestForecastByBoundingBox(t *testing.T) {
+	t.Parallel()
+
+	data, err := ioutil.ReadFile("testdata/box.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const wantRequestURL = "/data/2.5/box/city/?bbox=-74.1,40.5,-73.7,40.9,10&appid=0123456789abcdef0123456789abcdef"
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestURL := r.URL.String()
+		if wantRequestURL != gotRequestURL {
+			t.Errorf("Want %q, got %q comparing API URI", wantRequestURL, gotRequestURL)
+		}
+		_, err := w.Write(data)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithTempUnit(weather.TempUnitCelsius),
+		weather.WithSpeedUnit(weather.SpeedUnitMeters),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.ForecastByBoundingBox(40.5, -74.1, 40.9, -73.7, 10)
+	if err != nil {
+		t.Fatalf("Error while getting forecast for bounding box: %v", err)
+	}
+
+	want := []weather.Conditions{
+		{
+			Description:      stringPtr("overcast clouds"),
+			ShortDescription: stringPtr("Clouds"),
+			Temperature:      float64Ptr(12.9),
+			FeelsLike:        float64Ptr(12.6),
+			Humidity:         float64Ptr(92),
+			WindSpeed:        float64Ptr(2.5),
+			WindDirection:    float64Ptr(240),
+			Latitude:         float64Ptr(40.7143),
+			Longitude:        float64Ptr(-74.006),
+			Time:             time.Unix(1618110000, 0),
+			TempUnit:         weather.TempUnitCelsius,
+			SpeedUnit:        weather.SpeedUnitMeters,
+		},
+		{
+			Description:      stringPtr("light rain"),
+			ShortDescription: stringPtr("Rain"),
+			Temperature:      float64Ptr(11.4),
+			FeelsLike:        float64Ptr(10.8),
+			Humidity:         float64Ptr(88),
+			WindSpeed:        float64Ptr(3.1),
+			WindDirection:    float64Ptr(250),
+			Latitude:         float64Ptr(40.7868),
+			Longitude:        float64Ptr(-73.7265),
+			Time:             time.Unix(1618110000, 0),
+			TempUnit:         weather.TempUnitCelsius,
+			SpeedUnit:        weather.SpeedUnitMeters,
+		},
+	}
+	if len(want) != len(got) {
+		t.Fatalf("Want %d conditions, got %d", len(want), len(got))
+	}
+	for i := range want {
+		// Temperatures are compared as formatted in a forecast.
+		*got[i].Temperature = roundTenth(*got[i].Temperature)
+		*got[i].FeelsLike = roundTenth(*got[i].FeelsLike)
+		if !want[i].Equal(got[i]) {
+			t.Errorf("Want %+v, got %+v", want[i], got[i])
+		}
+	}
+
+	_, err = wc.ForecastByBoundingBox(40.9, -74.1, 40.5, -73.7, 10)
+	if err == nil {
+		t.Error("Want an error for a bounding box with its corners reversed, got nil")
+	}
+}
+
+func TestForecastByBoundingBoxStrict(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		body        string
+		wantErr     error
+	}{
+		{
+			description: "error code with HTTP 200",
+			body:        `{"cod": "404", "message": "city not found"}`,
+			wantErr:     weather.ErrLocationNotFound,
+		},
+		{
+			description: "unknown field",
+			body:        `{"cnt": 0, "calctime": 0.3, "list": []}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		ts := newTestServerWithBody(t, []byte(tc.body))
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithDisallowUnknownFields(true),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v, testing %v", err, tc.description)
+		}
+
+		_, err = wc.ForecastByBoundingBox(40.5, -74.1, 40.9, -73.7, 10)
+		if err == nil {
+			t.Errorf("Want an error, got nil, testing %v", tc.description)
+		}
+		if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+			t.Errorf("Want an error matching %v, got %v, testing %v", tc.wantErr, err, tc.description)
+		}
+	}
+}
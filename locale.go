@@ -0,0 +1,60 @@
+package weather
+
+import (
+	"fmt"
+	"strings"
+)
+
+// localeUnits stores the units of a measurement system.
+type localeUnits struct {
+	temp  TempUnit
+	speed SpeedUnit
+}
+
+// imperialCountries stores the ISO 3166 country codes of countries which use
+// imperial units. All others use metric units.
+var imperialCountries = map[string]bool{
+	"US": true,
+	"LR": true,
+	"MM": true,
+}
+
+// WithLocaleDefaults sets the units of the weather client to the measurement
+// system of a locale, such as "en_US.UTF-8" or "fr-FR," or of a country code,
+// such as "US" or "GB." The United States, Liberia, and Myanmar use imperial
+// units (Fahrenheit and miles/hour), and other countries use metric units
+// (Celsius and meters/sec). Units set by WithTempUnit or WithSpeedUnit take
+// precedence, regardless of the order of options.
+func WithLocaleDefaults(localeOrCountry string) clientOption {
+	return func(c *Client) error {
+		country, err := localeCountry(localeOrCountry)
+		if err != nil {
+			return err
+		}
+
+		c.localeUnits = &localeUnits{temp: TempUnitCelsius, speed: SpeedUnitMeters}
+		if imperialCountries[country] {
+			c.localeUnits = &localeUnits{temp: TempUnitFahrenheit, speed: SpeedUnitMiles}
+		}
+		return nil
+	}
+}
+
+// localeCountry returns the upper-case country code of a locale or country
+// code. The country is the part of a locale after the language, and before
+// any encoding or modifier, such as "US" in "en_US.UTF-8@euro."
+func localeCountry(s string) (string, error) {
+	locale := s
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.LastIndexAny(locale, "-_"); i >= 0 {
+		locale = locale[i+1:]
+	}
+
+	country := strings.ToUpper(locale)
+	if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("locale %q does not have a two-letter country code, please specify a locale such as en_US or a country such as US", s)
+	}
+	return country, nil
+}
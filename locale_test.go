@@ -0,0 +1,75 @@
+package weather_test
+
+import (
+	"testing"
+	"weather"
+)
+
+func TestWithLocaleDefaults(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		newClient   func() (*weather.Client, error)
+		wantTemp    weather.TempUnit
+		wantSpeed   weather.SpeedUnit
+	}{
+		{
+			description: "US is imperial",
+			newClient: func() (*weather.Client, error) {
+				return weather.NewClient(testAPIKey, weather.WithLocaleDefaults("US"))
+			},
+			wantTemp:  weather.TempUnitFahrenheit,
+			wantSpeed: weather.SpeedUnitMiles,
+		},
+		{
+			description: "GB is metric",
+			newClient: func() (*weather.Client, error) {
+				return weather.NewClient(testAPIKey, weather.WithLocaleDefaults("GB"))
+			},
+			wantTemp:  weather.TempUnitCelsius,
+			wantSpeed: weather.SpeedUnitMeters,
+		},
+		{
+			description: "FR locale is metric",
+			newClient: func() (*weather.Client, error) {
+				return weather.NewClient(testAPIKey, weather.WithLocaleDefaults("fr_FR.UTF-8"))
+			},
+			wantTemp:  weather.TempUnitCelsius,
+			wantSpeed: weather.SpeedUnitMeters,
+		},
+		{
+			description: "explicit units before locale win",
+			newClient: func() (*weather.Client, error) {
+				return weather.NewClient(testAPIKey, weather.WithTempUnit(weather.TempUnitKelvin), weather.WithSpeedUnit(weather.SpeedUnitMeters), weather.WithLocaleDefaults("en-us"))
+			},
+			wantTemp:  weather.TempUnitKelvin,
+			wantSpeed: weather.SpeedUnitMeters,
+		},
+		{
+			description: "explicit temperature unit after locale wins",
+			newClient: func() (*weather.Client, error) {
+				return weather.NewClient(testAPIKey, weather.WithLocaleDefaults("US"), weather.WithTempUnit(weather.TempUnitCelsius))
+			},
+			wantTemp:  weather.TempUnitCelsius,
+			wantSpeed: weather.SpeedUnitMiles,
+		},
+	}
+
+	for _, tc := range testCases {
+		wc, err := tc.newClient()
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client, testing %v: %v", tc.description, err)
+		}
+		if tc.wantTemp != wc.GetTempUnit() || tc.wantSpeed != wc.GetSpeedUnit() {
+			t.Errorf("Want %v and %v, got %v and %v, testing %v", tc.wantTemp, tc.wantSpeed, wc.GetTempUnit(), wc.GetSpeedUnit(), tc.description)
+		}
+	}
+
+	for _, locale := range []string{"", "C", "USA", "en_U1"} {
+		_, err := weather.NewClient(testAPIKey, weather.WithLocaleDefaults(locale))
+		if err == nil {
+			t.Errorf("Want an error for locale %q, got nil", locale)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// oneCallURI is the OpenWeatherMap.org One Call API, which is queried by
+// coordinates instead of location name.
+const oneCallURI = "/data/3.0/onecall"
+
+// MinuteForecast stores the forecast precipitation for one minute.
+type MinuteForecast struct {
+	Time time.Time
+	// Precipitation is in millimeters/hour.
+	Precipitation float64
+}
+
+// owmOneCallResponse stores fields from the OpenWeatherMap.org One Call API.
+// This does not fully mirror the API!
+type owmOneCallResponse struct {
+	Minutely []struct {
+		Dt            int64
+		Precipitation float64
+	}
+}
+
+// oneCallParts stores the parts of a One Call API response, which can be
+// excluded to reduce the size of the response.
+var oneCallParts = []string{"current", "minutely", "hourly", "daily", "alerts"}
+
+// formOneCallURL returns a One Call API URL for the specified coordinates,
+// excluding all parts of the response except the specified one.
+func (c Client) formOneCallURL(lat, lon float64, part string) string {
+	var exclude []string
+	for _, p := range oneCallParts {
+		if p != part {
+			exclude = append(exclude, p)
+		}
+	}
+
+	return fmt.Sprintf("%s%s/?%s=%s&%s=%s&%s=%s&%s=%s", c.APIHost, oneCallURI,
+		c.paramName("lat"), strconv.FormatFloat(lat, 'f', -1, 64),
+		c.paramName("lon"), strconv.FormatFloat(lon, 'f', -1, 64),
+		c.paramName("exclude"), strings.Join(exclude, ","),
+		c.paramName("appid"), c.APIKey)
+}
+
+// MinutelyPrecipitation accepts coordinates and returns the forecast
+// precipitation for each minute of the next hour. This uses the One Call API,
+// which requires a separate OpenWeatherMap.org subscription; without one, a
+// SubscriptionRequiredError is returned.
+func (c *Client) MinutelyPrecipitation(lat, lon float64) ([]MinuteForecast, error) {
+	data, status, err := c.fetchStatus(context.Background(), c.formOneCallURL(lat, lon, "minutely"))
+	if status == http.StatusUnauthorized && subscriptionRequired(data) {
+		err = &SubscriptionRequiredError{Endpoint: oneCallURI}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error querying weather API for minutely precipitation at %v,%v: %w", lat, lon, err)
+	}
+
+	var ar owmOneCallResponse
+	err = c.decodeJSON(data, &ar)
+	if err == nil && len(ar.Minutely) == 0 {
+		err = fmt.Errorf("unexpected empty `Minutely` from weather API: %+v", ar)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error querying weather API for minutely precipitation at %v,%v: %w", lat, lon, err)
+	}
+
+	forecast := make([]MinuteForecast, len(ar.Minutely))
+	for i, m := range ar.Minutely {
+		forecast[i] = MinuteForecast{
+			Time:          time.Unix(m.Dt, 0).UTC(),
+			Precipitation: m.Precipitation,
+		}
+	}
+	return forecast, nil
+}
+
+// subscriptionRequired returns true if the body of a weather API response
+// says that the endpoint requires a subscription, as the One Call API does for
+// free API keys.
+func subscriptionRequired(data []byte) bool {
+	var resp struct {
+		Message owmString
+	}
+	if json.Unmarshal(data, &resp) != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(resp.Message)), "subscription")
+}
+
+// NextRainStart returns how long after the first minute of a minutely
+// forecast precipitation begins, and false if there is no precipitation
+// forecast.
+func NextRainStart(data []MinuteForecast) (time.Duration, bool) {
+	for _, m := range data {
+		if m.Precipitation > 0 {
+			return m.Time.Sub(data[0].Time), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,132 @@
+package weather_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"weather"
+)
+
+func TestMinutelyPrecipitation(t *testing.T) {
+	t.Parallel()
+
+	const wantRequestURL = "/data/3.0/onecall/?lat=40.7868&lon=-73.7265&exclude=current,hourly,daily,alerts&appid=0123456789abcdef0123456789abcdef"
+
+	data, err := ioutil.ReadFile("testdata/minutely.json")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestURL := r.URL.String()
+		if wantRequestURL != gotRequestURL {
+			t.Errorf("Want %q, got %q comparing API URI", wantRequestURL, gotRequestURL)
+		}
+		_, err := w.Write(data)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.MinutelyPrecipitation(40.7868, -73.7265)
+	if err != nil {
+		t.Fatalf("Error while getting minutely precipitation: %v", err)
+	}
+
+	if len(got) != 61 {
+		t.Errorf("Want 61 minutes of precipitation, got %d", len(got))
+	}
+
+	wantFirst := weather.MinuteForecast{Time: time.Unix(1618110000, 0).UTC()}
+	if wantFirst != got[0] {
+		t.Errorf("Want %+v, got %+v comparing the first minute", wantFirst, got[0])
+	}
+
+	start, ok := weather.NextRainStart(got)
+	if !ok {
+		t.Fatalf("Want rain to start, got no rain")
+	}
+	if start != 12*time.Minute {
+		t.Errorf("Want rain to start in %v, got %v", 12*time.Minute, start)
+	}
+}
+
+func TestNextRainStartWithoutRain(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	data := []weather.MinuteForecast{
+		{Time: now},
+		{Time: now.Add(time.Minute)},
+	}
+
+	_, ok := weather.NextRainStart(data)
+	if ok {
+		t.Errorf("Want no rain for a forecast without precipitation")
+	}
+}
+
+func TestMinutelyPrecipitationSubscriptionRequired(t *testing.T) {
+	t.Parallel()
+
+	// This is the response to a free API key from the One Call 3.0 API.
+	const body = `{"cod":401, "message": "Please note that using One Call 3.0 requires a separate subscription to the One Call by Call plan. Learn more here https://openweathermap.org/price. If you have a valid subscription to the One Call by Call plan, but still receive this error, then please see https://openweathermap.org/faq#error401 for more info."}`
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte(body))
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	_, err = wc.MinutelyPrecipitation(40.7868, -73.7265)
+	var want *weather.SubscriptionRequiredError
+	if !errors.As(err, &want) {
+		t.Fatalf("Want a SubscriptionRequiredError, got %T: %v", err, err)
+	}
+	if want.Endpoint != "/data/3.0/onecall" {
+		t.Errorf("Want endpoint %q, got %q", "/data/3.0/onecall", want.Endpoint)
+	}
+}
+
+func TestMinutelyPrecipitationInvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServerWithBody(t, []byte(`{"minutely": "not a list"}`))
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	_, err = wc.MinutelyPrecipitation(40.7868, -73.7265)
+	var want *json.UnmarshalTypeError
+	if !errors.As(err, &want) || !strings.HasPrefix(err.Error(), "Error querying weather API for minutely precipitation") {
+		t.Errorf("Want a wrapped JSON error, got %T: %v", err, err)
+	}
+}
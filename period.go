@@ -0,0 +1,128 @@
+package weather
+
+import (
+	"fmt"
+	"time"
+)
+
+// ForecastPeriod stores forecast conditions for a named time window, such as
+// "Tonight" or "Tomorrow Afternoon."
+type ForecastPeriod struct {
+	Name       string
+	Start, End time.Time
+	Conditions []Conditions
+}
+
+// Parts of a day used to name forecast periods. The night begins in the
+// evening and continues until the next morning.
+var dayParts = []struct {
+	name            string
+	start, duration time.Duration
+}{
+	{name: "Morning", start: 6 * time.Hour, duration: 6 * time.Hour},
+	{name: "Afternoon", start: 12 * time.Hour, duration: 6 * time.Hour},
+	{name: "Night", start: 18 * time.Hour, duration: 12 * time.Hour},
+}
+
+// periodStart returns the start time and name of the part of the day which
+// includes t, and the midnight of the day that part of the day belongs to.
+// Early morning hours are part of the previous day's night.
+func periodStart(t time.Time) (start time.Time, part string, day time.Time) {
+	day = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if t.Hour() < 6 {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	for i := len(dayParts) - 1; i >= 0; i-- {
+		start = day.Add(dayParts[i].start)
+		if !t.Before(start) {
+			return start, dayParts[i].name, day
+		}
+	}
+	// This is not reached, as early morning hours are part of a night.
+	return day, dayParts[0].name, day
+}
+
+// periodName returns a name such as "Tomorrow Morning" for a part of a day,
+// relative to the day of the first forecast period.
+func periodName(part string, day, firstDay time.Time) string {
+	// Rounding accounts for days which are not 24 hours long.
+	days := int(day.Sub(firstDay).Round(24*time.Hour) / (24 * time.Hour))
+
+	switch {
+	case days == 0 && part == "Night":
+		return "Tonight"
+	case days == 0:
+		return "Today " + part
+	case days == 1:
+		return "Tomorrow " + part
+	}
+	return fmt.Sprintf("%s %s", day.Weekday(), part)
+}
+
+// groupPeriods groups forecast conditions, which are in time order, into
+// named forecast periods. Conditions without a time are skipped.
+func groupPeriods(forecast []Conditions) []ForecastPeriod {
+	var periods []ForecastPeriod
+	var firstDay time.Time
+
+	for _, w := range forecast {
+		if w.Time.IsZero() {
+			continue
+		}
+
+		start, part, day := periodStart(w.Time)
+		if len(periods) == 0 {
+			firstDay = day
+		}
+
+		if len(periods) == 0 || !periods[len(periods)-1].Start.Equal(start) {
+			var duration time.Duration
+			for _, p := range dayParts {
+				if p.name == part {
+					duration = p.duration
+				}
+			}
+			periods = append(periods, ForecastPeriod{
+				Name:  periodName(part, day, firstDay),
+				Start: start,
+				End:   start.Add(duration),
+			})
+		}
+
+		p := &periods[len(periods)-1]
+		p.Conditions = append(p.Conditions, w)
+	}
+	return periods
+}
+
+// Representative returns the conditions which best represent a forecast
+// period: of the conditions with the most common description, the middle one
+// (the earlier of the two middle ones, for an even number). The zero
+// Conditions are returned for a period without conditions.
+func (p ForecastPeriod) Representative() Conditions {
+	mostCommon := mostCommon(p.Conditions, func(w Conditions) *string { return w.Description })
+
+	var matching []Conditions
+	for _, w := range p.Conditions {
+		if (w.Description == nil && mostCommon == nil) || (w.Description != nil && mostCommon != nil && *w.Description == *mostCommon) {
+			matching = append(matching, w)
+		}
+	}
+
+	if len(matching) == 0 {
+		return Conditions{}
+	}
+	return matching[(len(matching)-1)/2]
+}
+
+// NamedForecast accepts a location and returns the upcoming forecast grouped
+// into named periods, such as "Tonight" and "Tomorrow Morning," in the time
+// zone of the location.
+func (c *Client) NamedForecast(location string) ([]ForecastPeriod, error) {
+	forecast, err := c.HourlyForecast(location, maxForecastCount)
+	if err != nil {
+		return nil, err
+	}
+	return groupPeriods(forecast), nil
+}
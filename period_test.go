@@ -0,0 +1,121 @@
+package weather_test
+
+import (
+	"testing"
+	"time"
+	"weather"
+)
+
+func TestNamedForecast(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(t, "testdata/greatneck_8slots.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.NamedForecast("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting named forecast: %v", err)
+	}
+
+	// The test data begins at 11pm on April 10th, in a time zone 4 hours behind UTC.
+	tz := time.FixedZone("", -4*60*60)
+	want := []struct {
+		name                      string
+		start, end                time.Time
+		numConditions             int
+		representativeDescription string
+	}{
+		{
+			name:                      "Tonight",
+			start:                     time.Date(2021, time.April, 10, 18, 0, 0, 0, tz),
+			end:                       time.Date(2021, time.April, 11, 6, 0, 0, 0, tz),
+			numConditions:             3,
+			representativeDescription: "light rain",
+		},
+		{
+			name:                      "Tomorrow Morning",
+			start:                     time.Date(2021, time.April, 11, 6, 0, 0, 0, tz),
+			end:                       time.Date(2021, time.April, 11, 12, 0, 0, 0, tz),
+			numConditions:             2,
+			representativeDescription: "broken clouds",
+		},
+		{
+			// Tied descriptions are resolved by the first to occur.
+			name:                      "Tomorrow Afternoon",
+			start:                     time.Date(2021, time.April, 11, 12, 0, 0, 0, tz),
+			end:                       time.Date(2021, time.April, 11, 18, 0, 0, 0, tz),
+			numConditions:             2,
+			representativeDescription: "clear sky",
+		},
+		{
+			name:                      "Tomorrow Night",
+			start:                     time.Date(2021, time.April, 11, 18, 0, 0, 0, tz),
+			end:                       time.Date(2021, time.April, 12, 6, 0, 0, 0, tz),
+			numConditions:             1,
+			representativeDescription: "clear sky",
+		},
+	}
+
+	if len(want) != len(got) {
+		t.Fatalf("Want %d forecast periods, got %d: %+v", len(want), len(got), got)
+	}
+
+	for i, w := range want {
+		g := got[i]
+		if w.name != g.Name {
+			t.Errorf("Want name %q, got %q for period %d", w.name, g.Name, i)
+		}
+		if !w.start.Equal(g.Start) || !w.end.Equal(g.End) {
+			t.Errorf("Want %v to %v, got %v to %v for period %q", w.start, w.end, g.Start, g.End, w.name)
+		}
+		if w.numConditions != len(g.Conditions) {
+			t.Errorf("Want %d conditions, got %d for period %q", w.numConditions, len(g.Conditions), w.name)
+		}
+
+		r := g.Representative()
+		if r.Description == nil || w.representativeDescription != *r.Description {
+			t.Errorf("Want representative description %q, got %v for period %q", w.representativeDescription, r.Description, w.name)
+		}
+	}
+
+	// Of the two "light rain" conditions tonight, the earlier is representative.
+	const wantTemp = 53.8
+	r := got[0].Representative()
+	if r.Temperature == nil || wantTemp != roundTenth(*r.Temperature) {
+		t.Errorf("Want representative temperature %v, got %v", wantTemp, r.Temperature)
+	}
+}
+
+func TestRepresentativeEmptyPeriod(t *testing.T) {
+	t.Parallel()
+
+	var p weather.ForecastPeriod
+	got := p.Representative()
+	if got.Description != nil || got.Temperature != nil {
+		t.Errorf("Want zero conditions for an empty period, got %+v", got)
+	}
+}
+
+func TestRepresentativeTie(t *testing.T) {
+	t.Parallel()
+
+	// Both descriptions occur twice, so the first seen is the most common.
+	p := weather.ForecastPeriod{Conditions: []weather.Conditions{
+		{Description: stringPtr("overcast clouds")},
+		{Description: stringPtr("light rain")},
+		{Description: stringPtr("light rain")},
+		{Description: stringPtr("overcast clouds")},
+	}}
+
+	const want = "overcast clouds"
+	got := p.Representative()
+	if got.Description == nil || want != *got.Description {
+		t.Errorf("Want representative description %q, got %v", want, got.Description)
+	}
+}
@@ -0,0 +1,131 @@
+package weather
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// recorder writes weather API responses to a writer, for later replay.
+type recorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// WithRecorder sets the weather client to write each weather API request and
+// its response to w, for debugging or to share with support. Each is written
+// as a line with the method, URL with the API key redacted, HTTP status code,
+// and body length, followed by the body and a newline. Recordings can be read
+// back using Client.Replay. By default, requests are not recorded.
+func WithRecorder(w io.Writer) clientOption {
+	return func(c *Client) error {
+		if w == nil {
+			return fmt.Errorf("recorder writer is nil")
+		}
+		c.recorder = &recorder{w: w}
+		return nil
+	}
+}
+
+// record writes a weather API request and its response to the recorder of the
+// weather client, if there is one. Recording is for debugging, so an error
+// writing is logged instead of failing the request.
+func (c Client) record(method, apiURL string, status int, body []byte) {
+	if c.recorder == nil {
+		return
+	}
+
+	c.recorder.mu.Lock()
+	defer c.recorder.mu.Unlock()
+	_, err := fmt.Fprintf(c.recorder.w, "%s %s %d %d\n%s\n", method, c.redactAPIKey(apiURL), status, len(body), body)
+	if err != nil {
+		c.slogger().Error("unable to record weather API response", "error", err)
+	}
+}
+
+// Replay reads weather API responses written by WithRecorder, and returns
+// weather conditions for each successful forecast API response, in the units
+// set in the weather client. Responses from other weather API endpoints or to
+// methods other than GET, such as the HEAD request of Ping, and unsuccessful
+// responses, are skipped.
+func (c *Client) Replay(r io.Reader) ([]Conditions, error) {
+	br := bufio.NewReader(r)
+	var replayed []Conditions
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return replayed, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error reading recorded response %d: %w", n, err)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("recorded response %d has an invalid header %q", n, line)
+		}
+		status, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("recorded response %d has an invalid status: %w", n, err)
+		}
+		length, err := strconv.Atoi(fields[3])
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("recorded response %d has an invalid body length %q", n, fields[3])
+		}
+
+		// Read the body and its trailing newline.
+		body := make([]byte, length+1)
+		_, err = io.ReadFull(br, body)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading body of recorded response %d: %w", n, err)
+		}
+		body = body[:length]
+
+		u, err := url.Parse(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("recorded response %d has an invalid URL: %w", n, err)
+		}
+		if fields[0] != http.MethodGet || status != http.StatusOK || strings.TrimSuffix(u.Path, "/") != strings.TrimSuffix(c.APIURI, "/") {
+			continue
+		}
+
+		w, err := c.parseForecast(body)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing recorded response %d: %w", n, err)
+		}
+		replayed = append(replayed, c.prepareConditions(w))
+	}
+}
+
+// flusher is implemented by buffered writers, such as bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// Close flushes the recorder set by WithRecorder, if its writer is buffered,
+// and closes idle connections of the HTTP client, making the weather client
+// safe to discard. Defer Close after creating a weather client, so recorded
+// responses are not lost when the program exits.
+func (c *Client) Close() error {
+	if c.HTTPClient != nil {
+		c.HTTPClient.CloseIdleConnections()
+	}
+
+	if c.recorder == nil {
+		return nil
+	}
+	c.recorder.mu.Lock()
+	defer c.recorder.mu.Unlock()
+	if f, ok := c.recorder.w.(flusher); ok {
+		err := f.Flush()
+		if err != nil {
+			return fmt.Errorf("Error flushing recorder: %w", err)
+		}
+	}
+	return nil
+}
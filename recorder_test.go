@@ -0,0 +1,132 @@
+package weather_test
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"weather"
+)
+
+func TestRecorderReplay(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+
+	var recording bytes.Buffer
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithRecorder(&recording),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	var want []weather.Conditions
+	for _, location := range []string{"Great Neck Plaza,NY,US", "London"} {
+		w, err := wc.ForecastConditions(location)
+		if err != nil {
+			t.Fatalf("Error while getting forecast conditions for %q: %v", location, err)
+		}
+		want = append(want, w)
+	}
+
+	if strings.Contains(recording.String(), testAPIKey) {
+		t.Errorf("Want the API key redacted from the recording, got %q", recording.String())
+	}
+	if !strings.HasPrefix(recording.String(), "GET "+ts.URL+"/data/2.5/forecast/?q=Great+Neck+Plaza%2CNY%2CUS&appid=REDACTED&cnt=1 200 ") {
+		t.Errorf("Want the recording to begin with the request and status, got %q", recording.String())
+	}
+
+	got, err := wc.Replay(&recording)
+	if err != nil {
+		t.Fatalf("Error replaying recording: %v", err)
+	}
+	if len(want) != len(got) {
+		t.Fatalf("Want %d replayed conditions, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if !want[i].Equal(got[i]) {
+			t.Errorf("Want %+v, got %+v, testing replayed response %d", want[i], got[i], i)
+		}
+	}
+}
+
+func TestReplayInvalid(t *testing.T) {
+	t.Parallel()
+
+	wc, err := weather.NewClient(testAPIKey)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		recording   string
+		errExpected bool
+	}{
+		{
+			description: "empty",
+			recording:   "",
+		},
+		{
+			description: "unsuccessful response is skipped",
+			recording:   "GET https://api.openweathermap.org/data/2.5/forecast/?q=Nowhere&appid=REDACTED&cnt=1 404 8\nnotfound\n",
+		},
+		{
+			description: "invalid header",
+			recording:   "GET https://api.openweathermap.org/data/2.5/forecast/\n",
+			errExpected: true,
+		},
+		{
+			description: "truncated body",
+			recording:   "GET https://api.openweathermap.org/data/2.5/forecast/?q=London&appid=REDACTED&cnt=1 200 100\n{}\n",
+			errExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := wc.Replay(strings.NewReader(tc.recording))
+		errReceived := err != nil
+		if tc.errExpected != errReceived {
+			t.Errorf("Want error %v, got %v, testing %v", tc.errExpected, err, tc.description)
+		}
+		if len(got) != 0 {
+			t.Errorf("Want no replayed conditions, got %+v, testing %v", got, tc.description)
+		}
+	}
+}
+
+func TestCloseFlushesRecorder(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+
+	var recording bytes.Buffer
+	buffered := bufio.NewWriterSize(&recording, 64*1024)
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithRecorder(buffered),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	_, err = wc.ForecastConditions("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting forecast conditions: %v", err)
+	}
+	if recording.Len() != 0 {
+		t.Fatalf("Want the recording buffered before Close, got %d bytes written", recording.Len())
+	}
+
+	err = wc.Close()
+	if err != nil {
+		t.Fatalf("Error closing weather client: %v", err)
+	}
+	if !strings.HasPrefix(recording.String(), "GET "+ts.URL) {
+		t.Errorf("Want the recording flushed by Close, got %q", recording.String())
+	}
+}
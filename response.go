@@ -0,0 +1,57 @@
+package weather
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ResponseMeta stores metadata of an HTTP response from the weather API.
+// Fields which were not included in the response are zero, and ContentLength
+// is -1 if the length is unknown.
+type ResponseMeta struct {
+	StatusCode    int
+	ContentLength int64
+	ContentType   string
+	Date          time.Time
+}
+
+// lastResponseStore stores metadata of the last weather API response, for a
+// weather client which can be shared by goroutines.
+type lastResponseStore struct {
+	mu   sync.Mutex
+	meta ResponseMeta
+}
+
+// LastResponse returns metadata of the last HTTP response received from the
+// weather API, including unsuccessful responses, or the zero ResponseMeta if
+// there has been none. When the weather client is used concurrently, this is
+// whichever response was received last.
+func (c *Client) LastResponse() ResponseMeta {
+	if c.lastResponse == nil {
+		return ResponseMeta{}
+	}
+	c.lastResponse.mu.Lock()
+	defer c.lastResponse.mu.Unlock()
+	return c.lastResponse.meta
+}
+
+// storeLastResponse saves metadata of a weather API response, to be returned
+// by LastResponse.
+func (c Client) storeLastResponse(resp *http.Response) {
+	if c.lastResponse == nil {
+		return
+	}
+
+	meta := ResponseMeta{
+		StatusCode:    resp.StatusCode,
+		ContentLength: resp.ContentLength,
+		ContentType:   resp.Header.Get("Content-Type"),
+	}
+	// An invalid or missing date is left as the zero time.
+	meta.Date, _ = http.ParseTime(resp.Header.Get("Date"))
+
+	c.lastResponse.mu.Lock()
+	defer c.lastResponse.mu.Unlock()
+	c.lastResponse.meta = meta
+}
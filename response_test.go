@@ -0,0 +1,84 @@
+package weather_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"weather"
+)
+
+func TestLastResponse(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2021, time.April, 11, 3, 0, 0, 0, time.UTC)
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Date", date.Format(http.TimeFormat))
+		if r.URL.Query().Get("q") == "Nowhere" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write(testGreatNeckJSON)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	if got := wc.LastResponse(); got != (weather.ResponseMeta{}) {
+		t.Errorf("Want no response metadata before a request, got %+v", got)
+	}
+
+	_, err = wc.ForecastConditions("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting forecast conditions: %v", err)
+	}
+	want := weather.ResponseMeta{
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(len(testGreatNeckJSON)),
+		ContentType:   "application/json; charset=utf-8",
+		Date:          date,
+	}
+	got := wc.LastResponse()
+	// The time zone of the parsed date can differ.
+	if want.Date.Equal(got.Date) {
+		got.Date = want.Date
+	}
+	if want != got {
+		t.Errorf("Want %+v, got %+v", want, got)
+	}
+
+	// An unsuccessful response is also recorded.
+	_, err = wc.ForecastConditions("Nowhere")
+	if err == nil {
+		t.Fatal("Want an error for an unsuccessful response, got nil")
+	}
+	if got := wc.LastResponse().StatusCode; got != http.StatusNotFound {
+		t.Errorf("Want status code %d, got %d", http.StatusNotFound, got)
+	}
+
+	// This is most useful when run with `go test -race`.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = wc.ForecastConditions("Great Neck Plaza,NY,US")
+			_ = wc.LastResponse()
+		}()
+	}
+	wg.Wait()
+	if got := wc.LastResponse().StatusCode; got != http.StatusOK {
+		t.Errorf("Want status code %d after concurrent requests, got %d", http.StatusOK, got)
+	}
+}
@@ -0,0 +1,107 @@
+package weather
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// roadRiskURI is the OpenWeatherMap.org Road Risk API, which requires a
+// separate subscription.
+const roadRiskURI = "/data/2.5/roadrisk"
+
+// Waypoint is a point along a route, and the Unix time it will be reached.
+type Waypoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+	DT  int64   `json:"dt"`
+}
+
+// RoadRiskResult stores the road risk and weather conditions at a waypoint.
+// RiskLevel is the highest level of the weather alerts for the waypoint: 0
+// for none, 1 for low, 2 for moderate, or 3 for high.
+type RoadRiskResult struct {
+	Waypoint   Waypoint
+	RiskLevel  int
+	Conditions Conditions
+}
+
+// owmRoadRiskResponse stores fields from the OpenWeatherMap.org Road Risk
+// API. This does not fully mirror the API!
+type owmRoadRiskResponse []struct {
+	Dt int64
+	// Coord is latitude then longitude.
+	Coord   [2]float64
+	Weather struct {
+		Temp       owmFloat
+		Wind_speed owmFloat
+		Wind_deg   owmFloat
+	}
+	Alerts []struct {
+		Event       string
+		Event_level int
+	}
+}
+
+// RoadRiskForecast accepts the waypoints of a route, and returns the road
+// risk and weather conditions at each waypoint, in the units set in the
+// weather client. This uses the Road Risk API, which requires a separate
+// OpenWeatherMap.org subscription; without one, a SubscriptionRequiredError
+// is returned.
+func (c *Client) RoadRiskForecast(waypoints []Waypoint) ([]RoadRiskResult, error) {
+	if len(waypoints) == 0 {
+		return nil, fmt.Errorf("no waypoints specified for the road risk forecast")
+	}
+
+	reqBody, err := json.Marshal(struct {
+		Track []Waypoint `json:"track"`
+	}{waypoints})
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := fmt.Sprintf("%s%s/?%s=%s", c.APIHost, roadRiskURI, c.paramName("appid"), c.APIKey)
+	data, status, err := c.send(context.Background(), http.MethodPost, apiURL, bytes.NewReader(reqBody))
+	if status == http.StatusUnauthorized && subscriptionRequired(data) {
+		err = &SubscriptionRequiredError{Endpoint: roadRiskURI}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error querying weather API for road risk of %d waypoints: %w", len(waypoints), err)
+	}
+
+	var ar owmRoadRiskResponse
+	err = c.decodeJSON(data, &ar)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying weather API for road risk of %d waypoints: %w", len(waypoints), err)
+	}
+
+	results := make([]RoadRiskResult, len(ar))
+	for i, entry := range ar {
+		lat, lon := entry.Coord[0], entry.Coord[1]
+		w := Conditions{
+			Temperature:   entry.Weather.Temp.store(new(float64)),
+			WindSpeed:     entry.Weather.Wind_speed.store(new(float64)),
+			WindDirection: entry.Weather.Wind_deg.store(new(float64)),
+			Latitude:      &lat,
+			Longitude:     &lon,
+			Time:          time.Unix(entry.Dt, 0).UTC(),
+			TempUnit:      TempUnitKelvin,
+			SpeedUnit:     SpeedUnitMeters,
+		}
+
+		r := RoadRiskResult{
+			Waypoint:   Waypoint{Lat: lat, Lon: lon, DT: entry.Dt},
+			Conditions: c.prepareConditions(w),
+		}
+		for _, a := range entry.Alerts {
+			if a.Event_level > r.RiskLevel {
+				r.RiskLevel = a.Event_level
+			}
+		}
+		results[i] = r
+	}
+	return results, nil
+}
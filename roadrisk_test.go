@@ -0,0 +1,173 @@
+package weather_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"weather"
+)
+
+func TestRoadRiskForecast(t *testing.T) {
+	t.Parallel()
+
+	const body = `[
+  {
+    "dt": 1602702000,
+    "coord": [7.27, 44.04],
+    "weather": {"temp": 278.44, "wind_speed": 2.27, "wind_deg": 7, "precipitation_intensity": 0.38},
+    "alerts": [
+      {"sender_name": "METEO-FRANCE", "event": "Rain", "event_level": 2},
+      {"sender_name": "METEO-FRANCE", "event": "Flood", "event_level": 3}
+    ]
+  },
+  {
+    "dt": 1602705600,
+    "coord": [7.37, 45.04],
+    "weather": {"temp": 276.44, "wind_speed": 1.07, "wind_deg": 180},
+    "alerts": []
+  }
+]`
+
+	waypoints := []weather.Waypoint{
+		{Lat: 7.27, Lon: 44.04, DT: 1602702000},
+		{Lat: 7.37, Lon: 45.04, DT: 1602705600},
+	}
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Want method %q, got %q", http.MethodPost, r.Method)
+		}
+		if want, got := "/data/2.5/roadrisk/?appid="+testAPIKey, r.URL.String(); want != got {
+			t.Errorf("Want %q, got %q comparing API URI", want, got)
+		}
+
+		var req struct {
+			Track []weather.Waypoint
+		}
+		data, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = json.Unmarshal(data, &req)
+		}
+		if err != nil {
+			t.Errorf("unable to read request body: %v", err)
+		}
+		if len(req.Track) != len(waypoints) || req.Track[1] != waypoints[1] {
+			t.Errorf("Want track %+v, got %+v", waypoints, req.Track)
+		}
+
+		_, err = w.Write([]byte(body))
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithTempUnit(weather.TempUnitCelsius),
+		weather.WithSpeedUnit(weather.SpeedUnitMeters),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.RoadRiskForecast(waypoints)
+	if err != nil {
+		t.Fatalf("Error while getting road risk forecast: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Want 2 road risk results, got %d", len(got))
+	}
+
+	testCases := []struct {
+		description   string
+		got           weather.RoadRiskResult
+		wantWaypoint  weather.Waypoint
+		wantRiskLevel int
+		wantTemp      float64
+		wantWind      float64
+	}{
+		{
+			description:   "highest alert level",
+			got:           got[0],
+			wantWaypoint:  waypoints[0],
+			wantRiskLevel: 3,
+			wantTemp:      5.3,
+			wantWind:      2.3,
+		},
+		{
+			description:   "no alerts",
+			got:           got[1],
+			wantWaypoint:  waypoints[1],
+			wantRiskLevel: 0,
+			wantTemp:      3.3,
+			wantWind:      1.1,
+		},
+	}
+
+	for _, tc := range testCases {
+		if tc.wantWaypoint != tc.got.Waypoint {
+			t.Errorf("Want waypoint %+v, got %+v, testing %v", tc.wantWaypoint, tc.got.Waypoint, tc.description)
+		}
+		if tc.wantRiskLevel != tc.got.RiskLevel {
+			t.Errorf("Want risk level %d, got %d, testing %v", tc.wantRiskLevel, tc.got.RiskLevel, tc.description)
+		}
+		if tc.got.Conditions.Temperature == nil || tc.wantTemp != roundTenth(*tc.got.Conditions.Temperature) {
+			t.Errorf("Want temperature %v, got %v, testing %v", tc.wantTemp, tc.got.Conditions.Temperature, tc.description)
+		}
+		if tc.got.Conditions.WindSpeed == nil || tc.wantWind != roundTenth(*tc.got.Conditions.WindSpeed) {
+			t.Errorf("Want wind speed %v, got %v, testing %v", tc.wantWind, tc.got.Conditions.WindSpeed, tc.description)
+		}
+	}
+}
+
+func TestRoadRiskForecastSubscriptionRequired(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, err := w.Write([]byte(`{"cod": 401, "message": "Please note that using Road Risk API requires a separate subscription."}`))
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	_, err = wc.RoadRiskForecast([]weather.Waypoint{{Lat: 7.27, Lon: 44.04, DT: 1602702000}})
+	var want *weather.SubscriptionRequiredError
+	if !errors.As(err, &want) {
+		t.Errorf("Want a SubscriptionRequiredError, got %T: %v", err, err)
+	}
+}
+
+func TestRoadRiskForecastInvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServerWithBody(t, []byte(`{"not": "a list"}`))
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	_, err = wc.RoadRiskForecast([]weather.Waypoint{{Lat: 40.7868, Lon: -73.7265, DT: 1618110000}})
+	var want *json.UnmarshalTypeError
+	if !errors.As(err, &want) || !strings.HasPrefix(err.Error(), "Error querying weather API for road risk") {
+		t.Errorf("Want a wrapped JSON error, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,145 @@
+package weather
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// lastGoodStore stores the last response body successfully decoded from the
+// weather API for each query, keyed by cacheKey, so it can be used when the
+// weather API is unavailable.
+type lastGoodStore struct {
+	mu      sync.Mutex
+	entries map[string]lastGoodEntry
+}
+
+// lastGoodEntry stores a weather API response body and when it was received.
+type lastGoodEntry struct {
+	data     []byte
+	received time.Time
+}
+
+// WithStaleIfError sets the weather client to return the last results
+// successfully received for the same query, with conditions marked with
+// Conditions.IsStale, when the weather API cannot be reached or returns a
+// server error, and those results are no older than maxAge. Other errors, such
+// as an unknown location, an invalid API key, or a canceled context, are
+// always returned. A warning is logged at the info level when stale results
+// are returned, using the logger set by WithSlogLogger or else slog.Default.
+// Results are only kept in memory, for the life of the weather client.
+func WithStaleIfError(maxAge time.Duration) clientOption {
+	return func(c *Client) error {
+		if maxAge <= 0 {
+			return fmt.Errorf("stale-if-error maximum age %v is invalid, please specify a positive duration", maxAge)
+		}
+		c.staleIfError = maxAge
+		c.lastGood = &lastGoodStore{entries: make(map[string]lastGoodEntry)}
+		return nil
+	}
+}
+
+// cacheKey returns a key identifying a weather API query URL, which is the
+// SHA256 hex hash of the URL with its query parameters sorted and the appid
+// parameter removed, so that the API key is not stored in cached results.
+func (c Client) cacheKey(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err == nil {
+		q := u.Query()
+		q.Del(c.paramName("appid"))
+		// Encode sorts parameters by name.
+		u.RawQuery = q.Encode()
+		apiURL = u.String()
+	}
+	sum := sha256.Sum256([]byte(apiURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// store saves a weather API response body as the last known good result for
+// a cache key.
+func (s *lastGoodStore) store(key string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = lastGoodEntry{data: data, received: time.Now()}
+}
+
+// load returns the last known good weather API response body for a cache
+// key, and false if there is none no older than maxAge.
+func (s *lastGoodStore) load(key string, maxAge time.Duration) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || time.Since(e.received) > maxAge {
+		return nil, false
+	}
+	return e.data, true
+}
+
+// staleOnError accepts the response body and error of a weather API query.
+// The body is stored if there is no error. An error is replaced with the last
+// good response body for the same query, and true, if the weather client is
+// configured to do so, the error is eligible according to staleEligible, and
+// a body no older than its maximum age is available. The API key is redacted
+// from the logged error.
+func (c Client) staleOnError(apiURL string, data []byte, err error) ([]byte, bool, error) {
+	if c.lastGood == nil {
+		return data, false, err
+	}
+	key := c.cacheKey(apiURL)
+	if err == nil {
+		c.lastGood.store(key, data)
+		return data, false, nil
+	}
+
+	if !staleEligible(err) {
+		return data, false, err
+	}
+	stale, ok := c.lastGood.load(key, c.staleIfError)
+	if !ok {
+		return data, false, err
+	}
+	c.slogger().Info("warning: returning stale weather conditions because the weather API returned an error", slog.String("error", c.redactAPIKey(err.Error())))
+	return stale, true, nil
+}
+
+// staleEligible returns true if err means the weather API could not be
+// reached or failed with a server error, for which stale conditions can be
+// returned instead. Cancellation and deadlines of the caller, and errors about
+// the query itself such as an unknown location or invalid API key, are not
+// eligible so they are seen by the caller.
+func staleEligible(err error) bool {
+	var canceled *ContextCanceledError
+	var deadline *DeadlineExceededError
+	if errors.As(err, &canceled) || errors.As(err, &deadline) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrLocationNotFound) || errors.Is(err, ErrInvalidAPIKey) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// slogger returns the logger of the weather client, or the default slog
+// logger if there is none.
+func (c Client) slogger() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,324 @@
+package weather_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"weather"
+)
+
+// newFailingTestServer returns a test HTTP server which serves the file
+// fileName as though it were the weather API for the first request, then
+// returns an error status for all later requests.
+func newFailingTestServer(t *testing.T, fileName string) *httptest.Server {
+	t.Helper()
+
+	return newTestServerFailingWith(t, fileName, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+}
+
+// newTestServerFailingWith returns a test HTTP server which serves the file
+// fileName as though it were the weather API for the first request, then
+// uses fail to respond to all later requests.
+func newTestServerFailingWith(t *testing.T, fileName string, fail http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var requests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			fail(w, r)
+			return
+		}
+		_, err := w.Write(data)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestStaleIfError(t *testing.T) {
+	t.Parallel()
+
+	ts := newFailingTestServer(t, "testdata/greatneck.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithStaleIfError(time.Hour),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	fresh, err := wc.ForecastConditions("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting forecast conditions: %v", err)
+	}
+	if fresh.IsStale {
+		t.Error("Want fresh conditions from the weather API, got stale conditions")
+	}
+
+	stale, err := wc.ForecastConditions("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Want stale conditions when the weather API returns an error, got error: %v", err)
+	}
+	if !stale.IsStale {
+		t.Error("Want stale conditions when the weather API returns an error, got IsStale false")
+	}
+	stale.IsStale = false
+	if !fresh.Equal(stale) {
+		t.Errorf("Want stale conditions %+v, got %+v", fresh, stale)
+	}
+
+	// Stale conditions are only returned for the same query.
+	_, err = wc.ForecastConditions("London")
+	if err == nil {
+		t.Error("Want an error for a location without earlier conditions, got nil")
+	}
+
+	// Ping is not hidden by stale conditions.
+	err = wc.Ping()
+	if err == nil {
+		t.Error("Want an error pinging a weather API which returns an error, got nil")
+	}
+}
+
+func TestStaleIfErrorMaxAge(t *testing.T) {
+	t.Parallel()
+
+	ts := newFailingTestServer(t, "testdata/greatneck.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithStaleIfError(time.Nanosecond),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	_, err = wc.ForecastConditions("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting forecast conditions: %v", err)
+	}
+
+	time.Sleep(time.Millisecond)
+	_, err = wc.ForecastConditions("Great Neck Plaza,NY,US")
+	if err == nil {
+		t.Error("Want an error when earlier conditions are older than the maximum age, got nil")
+	}
+
+	_, err = weather.NewClient(testAPIKey, weather.WithStaleIfError(0))
+	if err == nil {
+		t.Error("Want an error for a maximum age of 0, got nil")
+	}
+}
+
+func TestStaleIfErrorEligibleErrors(t *testing.T) {
+	t.Parallel()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		description string
+		fail        http.HandlerFunc
+		ctx         context.Context
+		wantStale   bool
+		wantErr     error
+	}{
+		{
+			description: "server error",
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantStale: true,
+		},
+		{
+			description: "connection closed",
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err != nil {
+					t.Errorf("unable to hijack test HTTP server connection: %v", err)
+					return
+				}
+				conn.Close()
+			},
+			wantStale: true,
+		},
+		{
+			description: "invalid API key",
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"cod": 401, "message": "Invalid API key."}`)
+			},
+			wantErr: weather.ErrInvalidAPIKey,
+		},
+		{
+			description: "location not found",
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"cod": "404", "message": "city not found"}`)
+			},
+			wantErr: weather.ErrLocationNotFound,
+		},
+		{
+			description: "rate limited",
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+		},
+		{
+			description: "canceled",
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			ctx:     canceled,
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tc := range testCases {
+		ts := newTestServerFailingWith(t, "testdata/greatneck.json", tc.fail)
+		var logOutput bytes.Buffer
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithStaleIfError(time.Hour),
+			weather.WithSlogLogger(slog.New(slog.NewTextHandler(&logOutput, nil))),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v, testing %v", err, tc.description)
+		}
+
+		_, err = wc.ForecastConditions("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting forecast conditions: %v, testing %v", err, tc.description)
+		}
+
+		ctx := tc.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		got, err := wc.ForecastConditionsWithContext(ctx, "Great Neck Plaza,NY,US")
+		if tc.wantStale {
+			if err != nil || !got.IsStale {
+				t.Errorf("Want stale conditions, got %+v and error %v, testing %v", got, err, tc.description)
+			}
+		} else if err == nil {
+			t.Errorf("Want an error instead of stale conditions, got %+v, testing %v", got, tc.description)
+		}
+		if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+			t.Errorf("Want an error matching %v, got %v, testing %v", tc.wantErr, err, tc.description)
+		}
+		if strings.Contains(logOutput.String(), testAPIKey) {
+			t.Errorf("Want the API key redacted from log output, got %q, testing %v", logOutput.String(), tc.description)
+		}
+	}
+}
+
+func TestStaleIfErrorQueries(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		file        string
+		// query returns any conditions from the query, which are checked
+		// for IsStale.
+		query func(wc *weather.Client) ([]weather.Conditions, error)
+	}{
+		{
+			description: "DaylightHours",
+			file:        "testdata/greatneck.json",
+			query: func(wc *weather.Client) ([]weather.Conditions, error) {
+				_, err := wc.DaylightHours("Great Neck Plaza,NY,US")
+				return nil, err
+			},
+		},
+		{
+			description: "WebURL",
+			file:        "testdata/greatneck.json",
+			query: func(wc *weather.Client) ([]weather.Conditions, error) {
+				_, err := wc.WebURL("Great Neck Plaza,NY,US")
+				return nil, err
+			},
+		},
+		{
+			description: "HourlyForecast",
+			file:        "testdata/greatneck.json",
+			query: func(wc *weather.Client) ([]weather.Conditions, error) {
+				return wc.HourlyForecast("Great Neck Plaza,NY,US", 1)
+			},
+		},
+		{
+			description: "AccumulatedPrecipitation",
+			file:        "testdata/greatneck.json",
+			query: func(wc *weather.Client) ([]weather.Conditions, error) {
+				_, err := wc.AccumulatedPrecipitation("Great Neck Plaza,NY,US")
+				return nil, err
+			},
+		},
+		{
+			description: "LongRangeForecast",
+			file:        "testdata/daily.json",
+			query: func(wc *weather.Client) ([]weather.Conditions, error) {
+				daily, err := wc.LongRangeForecast("Great Neck Plaza,NY,US", 3)
+				var forecast []weather.Conditions
+				for _, d := range daily {
+					forecast = append(forecast, weather.Conditions{IsStale: d.IsStale})
+				}
+				return forecast, err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		ts := newFailingTestServer(t, tc.file)
+		var logOutput bytes.Buffer
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithStaleIfError(time.Hour),
+			weather.WithSlogLogger(slog.New(slog.NewTextHandler(&logOutput, nil))),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v, testing %v", err, tc.description)
+		}
+
+		_, err = tc.query(wc)
+		if err != nil {
+			t.Fatalf("Error while querying the weather API: %v, testing %v", err, tc.description)
+		}
+		stale, err := tc.query(wc)
+		if err != nil {
+			t.Errorf("Want a stale result when the weather API returns an error, got error: %v, testing %v", err, tc.description)
+		}
+		for _, w := range stale {
+			if !w.IsStale {
+				t.Errorf("Want stale conditions, got IsStale false, testing %v", tc.description)
+			}
+		}
+
+		// Both queries are logged, and the second is a stale result.
+		if n := strings.Count(logOutput.String(), `msg="weather API query"`); n != 2 {
+			t.Errorf("Want 2 logged queries, got %d: %q, testing %v", n, logOutput.String(), tc.description)
+		}
+		if !strings.Contains(logOutput.String(), "cache_hit=true") {
+			t.Errorf("Want a logged stale result, got %q, testing %v", logOutput.String(), tc.description)
+		}
+	}
+}
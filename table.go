@@ -0,0 +1,136 @@
+package weather
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/tabwriter"
+)
+
+// LocationConditions stores the forecast conditions for a location, or the
+// error getting them.
+type LocationConditions struct {
+	Location   string
+	Conditions Conditions
+	Err        error
+}
+
+// ForecastAll accepts locations and returns forecast conditions for each,
+// in the same order, in the units set in the weather client. Locations are
+// queried concurrently, and an error for one location is stored in its
+// result without affecting the others.
+func (c *Client) ForecastAll(locations []string) []LocationConditions {
+	results := make([]LocationConditions, len(locations))
+	var wg sync.WaitGroup
+	for i, location := range locations {
+		wg.Add(1)
+		go func(i int, location string) {
+			defer wg.Done()
+			w, err := c.ForecastConditions(location)
+			results[i] = LocationConditions{Location: location, Conditions: w, Err: err}
+		}(i, location)
+	}
+	wg.Wait()
+	return results
+}
+
+// BatchForecast accepts locations and returns forecast conditions for each
+// location which could be forecast, keyed by location, in the units set in
+// the weather client. Locations are queried concurrently using ForecastAll.
+// If any location could not be forecast, the successful conditions are still
+// returned, along with a MultiError listing the error for each failed
+// location, in the order of locations.
+func (c *Client) BatchForecast(locations []string) (map[string]Conditions, error) {
+	forecast := make(map[string]Conditions, len(locations))
+	var errs []error
+	for _, r := range c.ForecastAll(locations) {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+			continue
+		}
+		forecast[r.Location] = r.Conditions
+	}
+
+	if len(errs) > 0 {
+		return forecast, &MultiError{Errs: errs}
+	}
+	return forecast, nil
+}
+
+// ForecastTable accepts locations and returns a text table of their
+// forecasts, with one row per location and columns aligned to the widest
+// value. Locations are queried concurrently using ForecastAll, and the row
+// for a location which could not be forecast shows "ERROR:" and the error.
+func ForecastTable(client *Client, locations []string) (string, error) {
+	if len(locations) == 0 {
+		return "", fmt.Errorf("no locations specified")
+	}
+
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOCATION\tDESCRIPTION\tTEMPERATURE\tHUMIDITY\tWIND")
+	for _, r := range client.ForecastAll(locations) {
+		if r.Err != nil {
+			fmt.Fprintf(tw, "%s\tERROR: %v\t\t\t\n", r.Location, r.Err)
+			continue
+		}
+
+		w := r.Conditions
+		var description, temp, humidity, wind string
+		if w.Description != nil {
+			description = *w.Description
+		}
+		if w.Temperature != nil {
+			temp = fmt.Sprintf("%.1f%v", *w.Temperature, tempUnitName[w.TempUnit])
+		}
+		if w.Humidity != nil {
+			humidity = fmt.Sprintf("%.1f%%", *w.Humidity)
+		}
+		if w.WindSpeed != nil {
+			wind = fmt.Sprintf("%.1f %v", *w.WindSpeed, speedUnitName[w.SpeedUnit])
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Location, description, temp, humidity, wind)
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// DailyForecastTable accepts a location and returns a text table of its
+// DailyForecast, with one row per day and columns aligned to the widest value.
+// The RAIN column is the RainLikelihood of the day.
+func DailyForecastTable(client *Client, location string) (string, error) {
+	forecast, err := client.DailyForecast(location)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tDESCRIPTION\tLOW\tHIGH\tHUMIDITY\tRAIN")
+	for _, d := range forecast {
+		var description, low, high, humidity string
+		if d.Description != nil {
+			description = *d.Description
+		}
+		if d.TempMin != nil {
+			low = fmt.Sprintf("%.1f%v", *d.TempMin, tempUnitName[d.TempUnit])
+		}
+		if d.TempMax != nil {
+			high = fmt.Sprintf("%.1f%v", *d.TempMax, tempUnitName[d.TempUnit])
+		}
+		if d.Humidity != nil {
+			humidity = fmt.Sprintf("%.1f%%", *d.Humidity)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", d.Date.Format("Mon Jan 2"), description, low, high, humidity, d.RainLikelihood())
+	}
+
+	err = tw.Flush()
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,153 @@
+package weather_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"weather"
+)
+
+func TestForecastTable(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := testGreatNeckJSON
+		if r.URL.Query().Get("q") == "Nowhere" {
+			body = []byte(`{"cod": "404", "message": "city not found"}`)
+		}
+		_, err := w.Write(body)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := weather.ForecastTable(wc, []string{"Great Neck Plaza,NY,US", "Nowhere", "London"})
+	if err != nil {
+		t.Fatalf("Error while getting forecast table: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	testCases := []struct {
+		location string
+		want     string
+	}{
+		{location: "LOCATION", want: "DESCRIPTION"},
+		{location: "Great Neck Plaza,NY,US", want: "overcast clouds"},
+		{location: "Nowhere", want: `ERROR: Error querying weather API for location "Nowhere": location not found: city not found`},
+		{location: "London", want: "overcast clouds"},
+	}
+	if len(testCases) != len(lines) {
+		t.Fatalf("Want %d lines, got %d: %q", len(testCases), len(lines), got)
+	}
+
+	// The second column begins after the widest location.
+	const column = len("Great Neck Plaza,NY,US  ")
+	for i, tc := range testCases {
+		if !strings.HasPrefix(lines[i], tc.location) || !strings.HasPrefix(lines[i][column:], tc.want) {
+			t.Errorf("Want line %d to be %q then %q at column %d, got %q", i, tc.location, tc.want, column, lines[i])
+		}
+	}
+	for _, i := range []int{1, 3} {
+		if !strings.HasSuffix(strings.TrimRight(lines[i], " "), "55.4 ºF      92.0%     5.6 mph") {
+			t.Errorf("Want line %d to end with temperature, humidity, and wind, got %q", i, lines[i])
+		}
+	}
+
+	_, err = weather.ForecastTable(wc, nil)
+	if err == nil {
+		t.Error("Want an error for no locations, got nil")
+	}
+}
+
+func TestBatchForecast(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := testGreatNeckJSON
+		if r.URL.Query().Get("q") == "Nowhere" {
+			body = []byte(`{"cod": "404", "message": "city not found"}`)
+		}
+		_, err := w.Write(body)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.BatchForecast([]string{"Great Neck Plaza,NY,US", "Nowhere"})
+
+	if len(got) != 1 {
+		t.Errorf("Want 1 successful forecast, got %d: %+v", len(got), got)
+	}
+	if w, ok := got["Great Neck Plaza,NY,US"]; !ok || !w.Equal(greatNeckConditions(t)) {
+		t.Errorf("Want conditions for Great Neck Plaza, got %+v", got)
+	}
+
+	var multiErr *weather.MultiError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("Want a MultiError, got %v", err)
+	}
+	if len(multiErr.Errs) != 1 {
+		t.Errorf("Want 1 error, got %d: %v", len(multiErr.Errs), multiErr.Errs)
+	}
+	if !errors.Is(err, weather.ErrLocationNotFound) {
+		t.Errorf("Want the error to match ErrLocationNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"Nowhere"`) {
+		t.Errorf("Want the error to name the failed location, got %q", err)
+	}
+
+	got, err = wc.BatchForecast([]string{"London"})
+	if err != nil {
+		t.Errorf("Want no error when every location succeeds, got %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("Want 1 successful forecast, got %d", len(got))
+	}
+}
+
+func TestDailyForecastTable(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(t, "testdata/greatneck_8slots.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithTempUnit(weather.TempUnitCelsius),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := weather.DailyForecastTable(wc, "Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting daily forecast table: %v", err)
+	}
+
+	const want = `DATE        DESCRIPTION      LOW      HIGH     HUMIDITY  RAIN
+Sat Apr 10  overcast clouds  12.9 ºC  12.9 ºC  92.0%     Unlikely
+Sun Apr 11  light rain       11.2 ºC  17.1 ºC  77.6%     Possible
+`
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+}
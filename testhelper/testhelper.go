@@ -0,0 +1,72 @@
+// Package testhelper provides helpers for testing code which uses the weather
+// package, such as a weather client for a fake weather API, and validating
+// test data.
+package testhelper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"weather"
+)
+
+// testAPIKey is formatted as an OpenWeatherMap API key, which the test
+// server does not check.
+const testAPIKey = "0123456789abcdef0123456789abcdef"
+
+// NewClientForTest returns a weather client which queries a TLS test server,
+// whose requests are served by handler as though it were the weather API.
+// The test server is closed when the test completes.
+func NewClientForTest(t *testing.T, handler http.Handler) *weather.Client {
+	t.Helper()
+
+	ts := httptest.NewTLSServer(handler)
+	t.Cleanup(ts.Close)
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client for test server: %v", err)
+	}
+	return wc
+}
+
+// ValidateFixture reads a file of JSON from the OpenWeatherMap.org API
+// `/2.5/forecast`, such as a test fixture, and returns an error if it can not
+// be parsed or is missing fields used by the weather client.
+func ValidateFixture(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	w, err := weather.ParseForecastJSON(data)
+	if err != nil {
+		return fmt.Errorf("fixture %s is not a valid forecast: %w", path, err)
+	}
+
+	var missing []string
+	for _, f := range []struct {
+		name    string
+		missing bool
+	}{
+		{"description", w.Description == nil},
+		{"temperature", w.Temperature == nil},
+		{"feels like", w.FeelsLike == nil},
+		{"humidity", w.Humidity == nil},
+		{"wind speed", w.WindSpeed == nil},
+	} {
+		if f.missing {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("fixture %s is missing the %s of the first forecast entry", path, strings.Join(missing, ", "))
+	}
+	return nil
+}
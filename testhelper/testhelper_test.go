@@ -0,0 +1,84 @@
+package testhelper_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"weather/testhelper"
+)
+
+func TestValidateFixture(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	testCases := []struct {
+		description string
+		body        string
+		wantErr     string
+	}{
+		{
+			description: "missing temperature and wind",
+			body:        `{"list": [{"dt": 1618110000, "main": {"feels_like": 285.74, "humidity": 92}, "weather": [{"main": "Clouds", "description": "overcast clouds"}]}]}`,
+			wantErr:     "missing the temperature, wind speed",
+		},
+		{
+			description: "empty list",
+			body:        `{"cod": "200", "list": []}`,
+			wantErr:     "not a valid forecast",
+		},
+		{
+			description: "malformed JSON",
+			body:        `{"list": [`,
+			wantErr:     "not a valid forecast",
+		},
+	}
+
+	err := testhelper.ValidateFixture("../testdata/greatneck.json")
+	if err != nil {
+		t.Errorf("Want no error validating greatneck.json, got %v", err)
+	}
+
+	for i, tc := range testCases {
+		path := filepath.Join(dir, fmt.Sprintf("%d.json", i))
+		err := os.WriteFile(path, []byte(tc.body), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = testhelper.ValidateFixture(path)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("Want an error containing %q, got %v, testing %v", tc.wantErr, err, tc.description)
+		}
+	}
+}
+
+func TestNewClientForTest(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("../testdata/greatneck.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wc := testhelper.NewClientForTest(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("q"); got != "London" {
+			t.Errorf("Want query %q, got %q", "London", got)
+		}
+		_, err := w.Write(data)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+
+	const want = "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph"
+	got, err := wc.Forecast("London")
+	if err != nil {
+		t.Fatalf("Error while getting forecast: %v", err)
+	}
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+}
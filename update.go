@@ -0,0 +1,124 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// releasesURL is the GitHub API endpoint for the latest release of this
+// client.
+const releasesURL = "https://api.github.com/repos/ivanfetch/weather-client/releases/latest"
+
+// githubRelease stores fields from the GitHub releases API.
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// CheckForUpdates queries GitHub for the latest release of this client, and
+// returns its version and URL if it is newer than currentVersion. Empty
+// strings are returned if currentVersion is already the latest. Versions are
+// compared as semantic versions, with or without a leading "v". Use ctx to
+// limit how long the check takes.
+func CheckForUpdates(ctx context.Context, currentVersion string) (latestVersion string, releaseURL string, err error) {
+	return checkForUpdates(ctx, http.DefaultClient, releasesURL, currentVersion)
+}
+
+// checkForUpdates queries the GitHub releases API at apiURL using client, and
+// returns the version and URL of the latest release if it is newer than
+// currentVersion.
+func checkForUpdates(ctx context.Context, client *http.Client, apiURL, currentVersion string) (latestVersion string, releaseURL string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("Error querying GitHub for the latest release: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("unexpected response status %q from GitHub releases API", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+
+	var release githubRelease
+	err = json.Unmarshal(data, &release)
+	if err != nil {
+		return "", "", err
+	}
+
+	newer, err := compareVersions(release.TagName, currentVersion)
+	if err != nil {
+		return "", "", err
+	}
+	if newer <= 0 {
+		return "", "", nil
+	}
+	return release.TagName, release.HTMLURL, nil
+}
+
+// parseVersion returns the major, minor, and patch numbers of a semantic
+// version, and whether it has a pre-release suffix. Build metadata is ignored.
+func parseVersion(v string) (nums [3]int, prerelease bool, err error) {
+	s := strings.TrimPrefix(v, "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		s = s[:i]
+		prerelease = true
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nums, false, fmt.Errorf("version %q is not a semantic version", v)
+	}
+	for i, p := range parts {
+		nums[i], err = strconv.Atoi(p)
+		if err != nil || nums[i] < 0 {
+			return nums, false, fmt.Errorf("version %q is not a semantic version", v)
+		}
+	}
+	return nums, prerelease, nil
+}
+
+// compareVersions returns a positive number if semantic version a is newer
+// than b, a negative number if it is older, and 0 if they are the same. A
+// pre-release is older than the same version without a pre-release suffix,
+// and pre-releases are not compared to each other.
+func compareVersions(a, b string) (int, error) {
+	aNums, aPre, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bNums, bPre, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range aNums {
+		if aNums[i] != bNums[i] {
+			return aNums[i] - bNums[i], nil
+		}
+	}
+	switch {
+	case aPre && !bPre:
+		return -1, nil
+	case !aPre && bPre:
+		return 1, nil
+	}
+	return 0, nil
+}
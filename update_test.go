@@ -0,0 +1,78 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		a, b string
+		want int
+	}{
+		{a: "v0.2.0", b: "0.1.0", want: 1},
+		{a: "v0.1.0", b: "0.1.0", want: 0},
+		{a: "0.1.0", b: "v0.10.0", want: -1},
+		{a: "1.0.0", b: "0.9.9", want: 1},
+		{a: "1.0.0-rc1", b: "1.0.0", want: -1},
+		{a: "1.0.0", b: "1.0.0-rc1", want: 1},
+		{a: "1.0.0+build5", b: "1.0.0", want: 0},
+	}
+
+	for _, tc := range testCases {
+		got, err := compareVersions(tc.a, tc.b)
+		if err != nil {
+			t.Fatalf("Error comparing %q to %q: %v", tc.a, tc.b, err)
+		}
+		// Only the sign of the comparison matters.
+		switch {
+		case got > 0:
+			got = 1
+		case got < 0:
+			got = -1
+		}
+		if tc.want != got {
+			t.Errorf("Want %d, got %d, comparing %q to %q", tc.want, got, tc.a, tc.b)
+		}
+	}
+
+	_, err := compareVersions("latest", "0.1.0")
+	if err == nil {
+		t.Error("Want an error comparing a version which is not semantic, got nil")
+	}
+}
+
+func TestCheckForUpdates(t *testing.T) {
+	t.Parallel()
+
+	const releaseURL = "https://github.com/ivanfetch/weather-client/releases/tag/v0.2.0"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": "v0.2.0", "html_url": %q}`, releaseURL)
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		currentVersion string
+		wantVersion    string
+		wantURL        string
+	}{
+		{currentVersion: "0.1.0", wantVersion: "v0.2.0", wantURL: releaseURL},
+		{currentVersion: "0.2.0", wantVersion: "", wantURL: ""},
+		{currentVersion: "v0.3.0", wantVersion: "", wantURL: ""},
+	}
+
+	for _, tc := range testCases {
+		gotVersion, gotURL, err := checkForUpdates(context.Background(), ts.Client(), ts.URL, tc.currentVersion)
+		if err != nil {
+			t.Fatalf("Error checking for updates from version %q: %v", tc.currentVersion, err)
+		}
+		if tc.wantVersion != gotVersion || tc.wantURL != gotURL {
+			t.Errorf("Want %q %q, got %q %q, checking for updates from version %q", tc.wantVersion, tc.wantURL, gotVersion, gotURL, tc.currentVersion)
+		}
+	}
+}
@@ -2,18 +2,40 @@
 package weather
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
+// version is the release of this package, and is updated before tagging a
+// release.
+const version = "0.1.0"
+
+// Version returns the release of this package.
+func Version() string {
+	return version
+}
+
 // SpeedUnit represents a unit of speed as an integer.
 type SpeedUnit int
 
@@ -33,6 +55,44 @@ const (
 	TempUnitKelvin
 )
 
+// String returns the name of a unit of speed, as accepted by
+// ProcessCLISpeedUnit.
+func (u SpeedUnit) String() string {
+	switch u {
+	case SpeedUnitMiles:
+		return "miles"
+	case SpeedUnitMeters:
+		return "meters"
+	}
+	return fmt.Sprintf("SpeedUnit(%d)", int(u))
+}
+
+// MarshalText implements encoding.TextMarshaler, to represent a unit of speed
+// by name.
+func (u SpeedUnit) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// String returns the name of a unit of temperature, as accepted by
+// ProcessCLITempUnit.
+func (u TempUnit) String() string {
+	switch u {
+	case TempUnitFahrenheit:
+		return "fahrenheit"
+	case TempUnitCelsius:
+		return "celsius"
+	case TempUnitKelvin:
+		return "kelvin"
+	}
+	return fmt.Sprintf("TempUnit(%d)", int(u))
+}
+
+// MarshalText implements encoding.TextMarshaler, to represent a unit of
+// temperature by name.
+func (u TempUnit) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
 // speedUnitName stores friendly names for the speedUnit... constants.
 var speedUnitName = map[SpeedUnit]string{
 	SpeedUnitMiles:  "mph",
@@ -46,37 +106,227 @@ var tempUnitName = map[TempUnit]string{
 	TempUnitKelvin:     "K",
 }
 
-// conditions stores API-agnostic weather conditions.
-type conditions struct {
-	description            *string
-	temperature, feelsLike *float64
-	humidity               *float64
-	windSpeed              *float64
+// Strategies for choosing between multiple entries returned by the weather
+// API, the first listed is the default.
+const (
+	SelectionFirst           = "first"
+	SelectionNearestNow      = "nearest-now"
+	SelectionAggregateMinMax = "aggregate-min-max"
+)
+
+// selectionStrategies stores the valid Selection... constants.
+var selectionStrategies = map[string]bool{
+	SelectionFirst:           true,
+	SelectionNearestNow:      true,
+	SelectionAggregateMinMax: true,
+}
+
+// Strategies for choosing the primary weather condition when the weather API
+// returns several for one time, such as rain and mist, the first listed is the
+// default.
+const (
+	PrimaryConditionFirst    = "first"
+	PrimaryConditionSeverity = "severity"
+)
+
+// primaryConditionStrategies stores the valid PrimaryCondition... constants.
+var primaryConditionStrategies = map[string]bool{
+	PrimaryConditionFirst:    true,
+	PrimaryConditionSeverity: true,
+}
+
+// Styles for formatting wind in a forecast, the first listed is the default.
+// WindStyleCompact is aviation-style, such as "240@12mph."
+const (
+	WindStyleDefault = "default"
+	WindStyleCompact = "compact"
+)
+
+// windStyles stores the valid WindStyle... constants.
+var windStyles = map[string]bool{
+	WindStyleDefault: true,
+	WindStyleCompact: true,
+}
+
+// owmFloat stores a number from the weather API and whether it was present,
+// which avoids allocating a *float64 for each field while parsing.
+type owmFloat struct {
+	value   float64
+	present bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A null number is not present.
+func (f *owmFloat) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("number %s from weather API is invalid: %v", data, err)
+	}
+	f.value, f.present = v, true
+	return nil
+}
+
+// store copies a number which is present to dst and returns dst, or returns
+// nil if the number is not present.
+func (f owmFloat) store(dst *float64) *float64 {
+	if !f.present {
+		return nil
+	}
+	*dst = f.value
+	return dst
+}
+
+// owmString stores a field of a weather API response which is a string in
+// some responses and a number in others, such as `cod` which can be "200" or
+// 401.
+type owmString string
+
+// UnmarshalJSON implements json.Unmarshaler, accepting a string or a number.
+func (s *owmString) UnmarshalJSON(data []byte) error {
+	// Values of a successful response are matched without allocating.
+	switch string(data) {
+	case "null":
+		return nil
+	case `"200"`, "200":
+		*s = "200"
+		return nil
+	case "0":
+		*s = "0"
+		return nil
+	}
+	var str string
+	if json.Unmarshal(data, &str) == nil {
+		*s = owmString(str)
+		return nil
+	}
+	*s = owmString(data)
+	return nil
+}
+
+// owmWeather stores a weather condition from the OpenWeatherMap.org APIs.
+type owmWeather struct {
+	// ID is the condition code, where the hundreds are its group, such as 5xx
+	// for rain.
+	ID          int
+	Main        *string
+	Description *string
+}
+
+// conditionSeverity stores how significant the condition groups are, by
+// their first digit, for the PrimaryConditionSeverity strategy. Clear sky is
+// 800, and clouds are 801 to 804.
+var conditionSeverity = map[int]int{
+	2: 6, // Thunderstorm
+	6: 5, // Snow
+	5: 4, // Rain
+	3: 3, // Drizzle
+	7: 2, // Atmosphere, such as fog
+	8: 1, // Clouds
+}
+
+// severity returns how significant a weather condition is, from 0 for clear
+// sky or an unknown condition to 6 for a thunderstorm.
+func (w owmWeather) severity() int {
+	if w.ID == 800 {
+		return 0
+	}
+	return conditionSeverity[w.ID/100]
+}
+
+// primaryWeather returns the index of the weather condition which describes a
+// forecast, using one of the PrimaryCondition... strategies. Ties in severity
+// go to the first condition. The conditions must not be empty.
+func primaryWeather(conditions []owmWeather, strategy string) int {
+	primary := 0
+	if strategy != PrimaryConditionSeverity {
+		return primary
+	}
+	for i := range conditions {
+		if conditions[i].severity() > conditions[primary].severity() {
+			primary = i
+		}
+	}
+	return primary
 }
 
 // owmResponse stores fields from the OpenWeatherMap.org API `/2.5/forecast`.
 // This does not fully mirror the API!
 type owmResponse struct {
-	List []struct {
-		Weather []struct {
-			Description *string
-		}
-		Main struct {
-			Temp       *float64
-			Feels_like *float64
-			Humidity   *float64
+	Cod     owmString
+	Message owmString
+	List    []struct {
+		Dt      int64
+		Weather []owmWeather
+		Main    struct {
+			Temp       owmFloat
+			Feels_like owmFloat
+			Humidity   owmFloat
 		}
 		Wind struct {
-			Speed *float64
+			Speed, Deg owmFloat
+		}
+		Pop owmFloat
+		// Rain and Snow are the volume for the last three hours, in mm.
+		Rain, Snow struct {
+			ThreeHour owmFloat `json:"3h"`
+		}
+	}
+	City struct {
+		ID    int
+		Coord struct {
+			Lat owmFloat
+			Lon owmFloat
 		}
+		// Timezone is the offset from UTC in seconds.
+		Timezone int
+		// Sunrise and Sunset are Unix times.
+		Sunrise, Sunset owmFloat
 	}
 }
 
+// RoundTripFunc performs an HTTP request to the weather API, and returns its
+// response.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RequestMiddleware wraps a RoundTripFunc, to add behavior before or after
+// requests to the weather API, such as logging. The middleware is responsible
+// for calling next.
+type RequestMiddleware func(next RoundTripFunc) RoundTripFunc
+
 // Client stores properties of a weather client.
 type Client struct {
 	APIKey, APIHost, APIURI string
 	speedUnit               SpeedUnit
 	tempUnit                TempUnit
+	speedUnitSet            bool
+	tempUnitSet             bool
+	localeUnits             *localeUnits
+	fieldSeparator          string
+	feedbackURL             string
+	maxResponseSize         int64
+	showCoordinates         bool
+	maxDescriptionLength    int
+	selectionStrategy       string
+	primaryCondition        string
+	calmWindLabel           bool
+	shortDescription        bool
+	clampHumidity           bool
+	disallowUnknownFields   bool
+	unitsFooter             bool
+	dualTempDisplay         bool
+	strictFormatting        bool
+	windStyle               string
+	staleIfError            time.Duration
+	lastGood                *lastGoodStore
+	logger                  *slog.Logger
+	locationAliases         map[string]string
+	paramNames              map[string]string
+	recorder                *recorder
+	lastResponse            *lastResponseStore
+	middleware              []RequestMiddleware
 	HTTPClient              *http.Client
 }
 
@@ -107,9 +357,279 @@ func WithHTTPClient(hc *http.Client) clientOption {
 	}
 }
 
+// WithTimeout sets the timeout of the HTTP client used by the weather client,
+// which defaults to 3 seconds. Set this after WithHTTPClient, as the timeout is
+// set on the HTTP client. The last of WithTimeout and WithNoTimeout wins.
+func WithTimeout(d time.Duration) clientOption {
+	return func(c *Client) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout %v must be greater than zero, use WithNoTimeout to disable the timeout", d)
+		}
+		if c.HTTPClient == nil {
+			return fmt.Errorf("HTTP client is nil, unable to set its timeout")
+		}
+		c.HTTPClient.Timeout = d
+		return nil
+	}
+}
+
+// WithNoTimeout disables the timeout of the HTTP client used by the weather
+// client. Without a timeout, a request to an unresponsive weather API can hang
+// indefinitely, so use the WithContext functions with a context deadline or
+// cancellation instead. Set this after WithHTTPClient, as the timeout is set
+// on the HTTP client. The last of WithTimeout and WithNoTimeout wins.
+func WithNoTimeout() clientOption {
+	return func(c *Client) error {
+		if c.HTTPClient == nil {
+			return fmt.Errorf("HTTP client is nil, unable to disable its timeout")
+		}
+		c.HTTPClient.Timeout = 0
+		return nil
+	}
+}
+
+// WithCalmWindLabel sets whether wind is described as "calm," instead of
+// showing a speed which rounds to zero.
+func WithCalmWindLabel(calm bool) clientOption {
+	return func(c *Client) error {
+		c.calmWindLabel = calm
+		return nil
+	}
+}
+
+// WithClampHumidity sets whether a humidity outside of 0 to 100 percent,
+// which a malformed weather API response could contain, is clamped to that
+// range. By default, such a humidity is an error.
+func WithClampHumidity(clamp bool) clientOption {
+	return func(c *Client) error {
+		c.clampHumidity = clamp
+		return nil
+	}
+}
+
+// WithDisallowUnknownFields sets whether decoding a forecast from the weather
+// API fails when the response contains a field this client does not use, to
+// catch changes to the weather API. This is strict, and only fields used by
+// the client are known, so OpenWeatherMap.org responses which include
+// additional fields will fail to decode. By default, unknown fields are
+// ignored.
+func WithDisallowUnknownFields(disallow bool) clientOption {
+	return func(c *Client) error {
+		c.disallowUnknownFields = disallow
+		return nil
+	}
+}
+
+// WithDualTempDisplay sets whether a formatted forecast shows temperatures in
+// both Celsius and Fahrenheit, such as "12.9 ºC / 55.4 ºF," regardless of the
+// temperature unit set in the weather client. The default is false.
+func WithDualTempDisplay(dual bool) clientOption {
+	return func(c *Client) error {
+		c.dualTempDisplay = dual
+		return nil
+	}
+}
+
+// WithFeedbackURL sets the endpoint used by ReportAccuracy. The default is an
+// empty string, which disables reporting.
+func WithFeedbackURL(u string) clientOption {
+	return func(c *Client) error {
+		c.feedbackURL = u
+		return nil
+	}
+}
+
+// WithFieldSeparator sets the separator placed between fields of a formatted
+// forecast. The default is ", ".
+func WithFieldSeparator(sep string) clientOption {
+	return func(c *Client) error {
+		c.fieldSeparator = sep
+		return nil
+	}
+}
+
+// WithLocationAliases adds friendly names for locations, such as "home,"
+// mapped to the location queried from the weather API, such as "Great Neck
+// Plaza,NY,US." Aliases are resolved by the forecast methods, and a formatted
+// forecast for an alias begins with the alias, such as "home: ." A location
+// which is not an alias is queried unchanged.
+func WithLocationAliases(aliases map[string]string) clientOption {
+	return func(c *Client) error {
+		for alias, location := range aliases {
+			err := WithLocationAlias(alias, location)(c)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithLocationAlias adds one friendly name for a location, such as "gnp" for
+// "Great Neck Plaza,NY,US." See WithLocationAliases.
+func WithLocationAlias(short, full string) clientOption {
+	return func(c *Client) error {
+		if full == "" {
+			return fmt.Errorf("location alias %q has an empty location", short)
+		}
+		if c.locationAliases == nil {
+			c.locationAliases = make(map[string]string)
+		}
+		c.locationAliases[short] = full
+		return nil
+	}
+}
+
+// paramNames stores the query parameters which the weather client sends to
+// the weather API, and which can be renamed using WithParamNames.
+var paramNames = map[string]bool{
+	"appid":   true,
+	"bbox":    true,
+	"cnt":     true,
+	"exclude": true,
+	"id":      true,
+	"lat":     true,
+	"lon":     true,
+	"q":       true,
+}
+
+// WithParamNames renames query parameters in weather API URLs, for proxies
+// which require different names, such as {"appid": "apikey"}. The keys are
+// the standard weather API parameter names; those not specified keep their
+// standard names.
+func WithParamNames(names map[string]string) clientOption {
+	return func(c *Client) error {
+		c.paramNames = make(map[string]string, len(names))
+		for param, name := range names {
+			if !paramNames[param] {
+				known := make([]string, 0, len(paramNames))
+				for p := range paramNames {
+					known = append(known, p)
+				}
+				sort.Strings(known)
+				return fmt.Errorf("query parameter %q is not used by the weather client, please specify one of %s", param, strings.Join(known, ", "))
+			}
+			if name == "" {
+				return fmt.Errorf("query parameter %q has an empty name", param)
+			}
+			c.paramNames[param] = name
+		}
+		return nil
+	}
+}
+
+// WithMaxDescriptionLength limits the number of characters in a weather
+// description, truncating longer descriptions with an ellipsis. The default of
+// 0 does not limit the description length.
+func WithMaxDescriptionLength(n int) clientOption {
+	return func(c *Client) error {
+		if n < 0 {
+			return fmt.Errorf("maximum description length %d is negative", n)
+		}
+		c.maxDescriptionLength = n
+		return nil
+	}
+}
+
+// WithMaxResponseSize limits the number of bytes read from a weather API
+// response. The default of 0 does not limit the response size.
+func WithMaxResponseSize(n int64) clientOption {
+	return func(c *Client) error {
+		if n < 0 {
+			return fmt.Errorf("maximum response size %d is negative", n)
+		}
+		c.maxResponseSize = n
+		return nil
+	}
+}
+
+// WithStrictFormatting sets whether formatting a forecast is an error, of
+// ErrNoWeatherData, when the weather conditions have no description or other
+// usable fields. By default, "no weather data available" is returned as the
+// forecast.
+func WithStrictFormatting(strict bool) clientOption {
+	return func(c *Client) error {
+		c.strictFormatting = strict
+		return nil
+	}
+}
+
+// WithMiddleware adds middleware which wraps each request to the weather
+// API. Middleware runs in the order it is added, the first added being the
+// outermost.
+func WithMiddleware(m ...RequestMiddleware) clientOption {
+	return func(c *Client) error {
+		c.middleware = append(c.middleware, m...)
+		return nil
+	}
+}
+
+// WithSelectionStrategy sets how a forecast is chosen when the weather API
+// returns multiple entries, which it occasionally does even when only one is
+// requested. Valid strategies are the `Selection...` package constants:
+// SelectionFirst uses the first entry (the default), SelectionNearestNow uses
+// the entry closest to the current time, and SelectionAggregateMinMax uses the
+// first entry with the temperature shown as the range across all entries.
+func WithSelectionStrategy(strategy string) clientOption {
+	return func(c *Client) error {
+		if !selectionStrategies[strategy] {
+			return fmt.Errorf("selection strategy %q is invalid, please use one of the SelectionFirst, SelectionNearestNow, or SelectionAggregateMinMax constants.", strategy)
+		}
+		c.selectionStrategy = strategy
+		return nil
+	}
+}
+
+// WithPrimaryConditionStrategy sets which weather condition describes a
+// forecast when the weather API returns several for one time, such as rain
+// and mist. Valid strategies are the `PrimaryCondition...` package constants:
+// PrimaryConditionFirst uses the first condition, in the order of the weather
+// API (the default), and PrimaryConditionSeverity uses the most significant
+// condition group, from thunderstorm, snow, rain, drizzle, atmosphere such as
+// fog, clouds, then clear.
+func WithPrimaryConditionStrategy(strategy string) clientOption {
+	return func(c *Client) error {
+		if !primaryConditionStrategies[strategy] {
+			return fmt.Errorf("primary condition strategy %q is invalid, please use one of the PrimaryConditionFirst or PrimaryConditionSeverity constants.", strategy)
+		}
+		c.primaryCondition = strategy
+		return nil
+	}
+}
+
+// WithShortDescription sets whether a formatted forecast uses the short
+// category of weather, such as "Clouds," instead of the longer description,
+// such as "overcast clouds."
+func WithShortDescription(short bool) clientOption {
+	return func(c *Client) error {
+		c.shortDescription = short
+		return nil
+	}
+}
+
+// WithShowCoordinates sets whether a formatted forecast ends with the
+// coordinates of the location resolved by the weather API.
+func WithShowCoordinates(show bool) clientOption {
+	return func(c *Client) error {
+		c.showCoordinates = show
+		return nil
+	}
+}
+
+// WithSlogLogger sets a structured logger, which receives a record for each
+// weather API query with the attributes url (with the API key redacted),
+// duration, status_code, and cache_hit. By default, queries are not logged.
+func WithSlogLogger(l *slog.Logger) clientOption {
+	return func(c *Client) error {
+		c.logger = l
+		return nil
+	}
+}
+
 // WithSpeedUnit sets the corresponding weather.client option.
 func WithSpeedUnit(u SpeedUnit) clientOption {
 	return func(c *Client) error {
+		c.speedUnitSet = true
 		return c.SetSpeedUnit(u)
 	}
 }
@@ -117,20 +637,71 @@ func WithSpeedUnit(u SpeedUnit) clientOption {
 // WithTempUnit sets the corresponding weather.client option.
 func WithTempUnit(u TempUnit) clientOption {
 	return func(c *Client) error {
+		c.tempUnitSet = true
 		return c.SetTempUnit(u)
 	}
 }
 
+// WithWindStyle sets how wind is formatted in a forecast. Valid styles are
+// the `WindStyle...` package constants: WindStyleDefault formats wind such as
+// "wind 5.6 mph," and WindStyleCompact formats it using FormatWind, such as
+// "wind 180@6mph."
+func WithWindStyle(style string) clientOption {
+	return func(c *Client) error {
+		if !windStyles[style] {
+			return fmt.Errorf("wind style %q is invalid, please use one of the WindStyleDefault or WindStyleCompact constants.", style)
+		}
+		c.windStyle = style
+		return nil
+	}
+}
+
+// WithUnitsFooter sets whether a formatted forecast ends with a line stating
+// its units, such as "[units: temp=ºF, wind=mph]," for downstream tools.
+func WithUnitsFooter(footer bool) clientOption {
+	return func(c *Client) error {
+		c.unitsFooter = footer
+		return nil
+	}
+}
+
+// ValidateAPIKeyFormat returns an error if an OpenWeatherMap API key is not
+// formatted as a 32 character hexadecimal string. This does not verify that
+// the key is valid with the weather API.
+func ValidateAPIKeyFormat(key string) error {
+	if len(key) != 32 {
+		return fmt.Errorf("API key is %d characters, an OpenWeatherMap API key is 32 hexadecimal characters", len(key))
+	}
+
+	for _, r := range key {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return fmt.Errorf("API key contains %q, an OpenWeatherMap API key is 32 hexadecimal characters", r)
+		}
+	}
+	return nil
+}
+
 // NewClient accepts an OpenWeatherMap API key and calls to functional options,
-// and returns a pointer to a new weather client.
+// and returns a pointer to a new weather client. The format of the API key is
+// validated, but the key is not verified with the weather API.
 func NewClient(APIKey string, options ...clientOption) (*Client, error) {
+	err := ValidateAPIKeyFormat(APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("%v (use Client.Ping() to verify that a key is valid with the weather API)", err)
+	}
+
 	c := &Client{
 		APIKey:  APIKey,
 		APIHost: "https://api.openweathermap.org",
-		APIURI:  "/data/2.5/forecast",
+		APIURI:  forecastURI,
 		// This non-default client and its timeout is used
 		// RE: https://medium.com/@nate510/don-t-use-go-s-default-http-client-4804cb19f779
-		HTTPClient: &http.Client{Timeout: time.Second * 3},
+		HTTPClient:        &http.Client{Timeout: time.Second * 3},
+		fieldSeparator:    ", ",
+		selectionStrategy: SelectionFirst,
+		primaryCondition:  PrimaryConditionFirst,
+		windStyle:         WindStyleDefault,
+		lastResponse:      &lastResponseStore{},
 	}
 
 	for _, o := range options {
@@ -139,6 +710,17 @@ func NewClient(APIKey string, options ...clientOption) (*Client, error) {
 			return nil, err
 		}
 	}
+
+	// Units from WithLocaleDefaults do not override those set explicitly,
+	// regardless of the order of options.
+	if c.localeUnits != nil {
+		if !c.tempUnitSet {
+			c.tempUnit = c.localeUnits.temp
+		}
+		if !c.speedUnitSet {
+			c.speedUnit = c.localeUnits.speed
+		}
+	}
 	return c, nil
 }
 
@@ -152,6 +734,69 @@ func (c *Client) GetTempUnit() TempUnit {
 	return c.tempUnit
 }
 
+// ClientConfig stores the effective configuration of a weather client, with
+// the API key redacted, for example to include in a bug report.
+type ClientConfig struct {
+	APIKey                string    `json:"api_key"`
+	APIHost               string    `json:"api_host"`
+	APIURI                string    `json:"api_uri"`
+	SpeedUnit             SpeedUnit `json:"speed_unit"`
+	TempUnit              TempUnit  `json:"temp_unit"`
+	Timeout               string    `json:"timeout"`
+	FieldSeparator        string    `json:"field_separator"`
+	FeedbackURL           string    `json:"feedback_url"`
+	MaxResponseSize       int64     `json:"max_response_size"`
+	ShowCoordinates       bool      `json:"show_coordinates"`
+	MaxDescriptionLength  int       `json:"max_description_length"`
+	SelectionStrategy     string    `json:"selection_strategy"`
+	PrimaryCondition      string    `json:"primary_condition"`
+	CalmWindLabel         bool      `json:"calm_wind_label"`
+	ShortDescription      bool      `json:"short_description"`
+	ClampHumidity         bool      `json:"clamp_humidity"`
+	DisallowUnknownFields bool      `json:"disallow_unknown_fields"`
+	UnitsFooter           bool      `json:"units_footer"`
+	DualTempDisplay       bool      `json:"dual_temp_display"`
+	StrictFormatting      bool      `json:"strict_formatting"`
+	WindStyle             string    `json:"wind_style"`
+	StaleIfError          string    `json:"stale_if_error,omitempty"`
+}
+
+// Config returns the configuration of a weather client. The API key is
+// redacted, and is only shown to be set or not.
+func (c *Client) Config() ClientConfig {
+	config := ClientConfig{
+		APIHost:               c.APIHost,
+		APIURI:                c.APIURI,
+		SpeedUnit:             c.speedUnit,
+		TempUnit:              c.tempUnit,
+		FieldSeparator:        c.fieldSeparator,
+		FeedbackURL:           c.feedbackURL,
+		MaxResponseSize:       c.maxResponseSize,
+		ShowCoordinates:       c.showCoordinates,
+		MaxDescriptionLength:  c.maxDescriptionLength,
+		SelectionStrategy:     c.selectionStrategy,
+		PrimaryCondition:      c.primaryCondition,
+		CalmWindLabel:         c.calmWindLabel,
+		ShortDescription:      c.shortDescription,
+		ClampHumidity:         c.clampHumidity,
+		DisallowUnknownFields: c.disallowUnknownFields,
+		UnitsFooter:           c.unitsFooter,
+		DualTempDisplay:       c.dualTempDisplay,
+		StrictFormatting:      c.strictFormatting,
+		WindStyle:             c.windStyle,
+	}
+	if c.staleIfError > 0 {
+		config.StaleIfError = c.staleIfError.String()
+	}
+	if c.APIKey != "" {
+		config.APIKey = "REDACTED"
+	}
+	if c.HTTPClient != nil {
+		config.Timeout = c.HTTPClient.Timeout.String()
+	}
+	return config
+}
+
 // SetSpeedUnit validates then sets the unit of speed for a weather client.
 // Valid units are in the range of `SpeedUnit...` package constants.
 func (c *Client) SetSpeedUnit(u SpeedUnit) error {
@@ -176,128 +821,1017 @@ func (c *Client) SetTempUnit(u TempUnit) error {
 
 // ConvertTemp converts Kelvin temperature to the unit set in a weatherclient.
 func (c Client) ConvertTemp(kelvin float64) float64 {
-	var t float64
-	switch c.tempUnit {
-	case TempUnitCelsius:
-		return kelvin - 273.15
-	case TempUnitFahrenheit:
-		return 1.8*(kelvin-273) + 32
-	case TempUnitKelvin:
-		// Input is already Kelvin
-		return kelvin
-	}
-	return t
+	return tempFromKelvin(kelvin, c.tempUnit)
 }
 
 // ConvertSpeed converts a speed from meters/sec to the unit set in a weather client.
 func (c Client) ConvertSpeed(meters float64) float64 {
-	var s float64
-	switch c.speedUnit {
-	case SpeedUnitMeters:
-		// Input is already meters/sec
-		return meters
-	case SpeedUnitMiles:
-		return meters * 2.236936
-	}
-	return s
+	return speedFromMeters(meters, c.speedUnit)
 }
 
-// queryAPI accepts an OpenWeatherMap.org URL and returns weather conditions.
-func (c Client) queryAPI(url string) (conditions, error) {
-	resp, err := c.HTTPClient.Get(url)
+// fetch accepts a weather API URL and returns the body of a successful
+// response.
+func (c Client) fetch(ctx context.Context, url string) ([]byte, error) {
+	data, _, err := c.fetchStatus(ctx, url)
 	if err != nil {
-		return conditions{}, err
+		return nil, err
 	}
+	return data, nil
+}
 
-	defer resp.Body.Close()
+// fetchStatus is fetch, also returning the HTTP status code of the response,
+// or 0 if there was no response. The body of a response with an unsuccessful
+// status is returned along with the error.
+func (c Client) fetchStatus(ctx context.Context, url string) ([]byte, int, error) {
+	return c.send(ctx, http.MethodGet, url, nil)
+}
 
-	// ioutil.ReadAll() returns a slice of bytes
-	data, err := ioutil.ReadAll(resp.Body)
+// send is fetchStatus, using the specified HTTP method and request body. A
+// request body is sent as JSON.
+func (c Client) send(ctx context.Context, method, url string, reqBody io.Reader) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return conditions{}, err
+		return nil, 0, err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		// Including the HTTP body can help by providing a message from the weather API.
-		return conditions{}, fmt.Errorf("HTTP %s returned from weather API: %v", resp.Status, string(data))
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	var ar owmResponse
-	err = json.Unmarshal(data, &ar)
+	resp, err := c.roundTripper()(req)
 	if err != nil {
-		return conditions{}, err
+		return nil, 0, classifyRequestError(ctx, err)
 	}
 
-	if len(ar.List) == 0 {
-		return conditions{}, fmt.Errorf("unexpected empty `List` from weather API: %+v", ar)
+	defer resp.Body.Close()
+	c.storeLastResponse(resp)
+
+	// Read one byte past the limit, to detect a response that exceeds it.
+	var body io.Reader = resp.Body
+	if c.maxResponseSize > 0 {
+		body = io.LimitReader(resp.Body, c.maxResponseSize+1)
+	}
+
+	// ioutil.ReadAll() returns a slice of bytes
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, resp.StatusCode, err
 	}
+	c.record(req.Method, url, resp.StatusCode, data)
 
-	if len(ar.List[0].Weather) == 0 {
-		return conditions{}, fmt.Errorf("unexpected empty List[0].Weather from weather API: %+v", ar)
+	if c.maxResponseSize > 0 && int64(len(data)) > c.maxResponseSize {
+		return nil, resp.StatusCode, fmt.Errorf("response body too large, exceeding the limit of %d bytes", c.maxResponseSize)
 	}
 
-	return conditions{
-		description: ar.List[0].Weather[0].Description,
-		temperature: ar.List[0].Main.Temp,
-		feelsLike:   ar.List[0].Main.Feels_like,
-		humidity:    ar.List[0].Main.Humidity,
-		windSpeed:   ar.List[0].Wind.Speed,
-	}, nil
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return data, resp.StatusCode, checkAPIKeyError(newAPIError(resp.StatusCode, resp.Status, data))
+	}
+	return data, resp.StatusCode, nil
 }
 
-// Forecast accepts a location and returns a forecast.
-func (c *Client) Forecast(location string) (string, error) {
-	url := fmt.Sprintf("%s%s/?q=%s&appid=%s&cnt=1", c.APIHost, c.APIURI, url.QueryEscape(location), c.APIKey)
+// roundTripper returns a RoundTripFunc which performs requests using the HTTP
+// client of the weather client, wrapped by any middleware.
+func (c Client) roundTripper() RoundTripFunc {
+	rt := RoundTripFunc(c.HTTPClient.Do)
+	// Wrap in reverse, so the first middleware added is the outermost.
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	return rt
+}
 
-	resp, err := c.queryAPI(url)
+// queryAPI accepts an OpenWeatherMap.org URL and returns weather conditions,
+// in the Kelvin and meters/sec units used by the weather API.
+func (c Client) queryAPI(ctx context.Context, url string) (Conditions, error) {
+	var w Conditions
+	stale, err := c.query(ctx, url, func(data []byte) error {
+		var err error
+		w, err = c.parseForecast(data)
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("Error querying weather API for location %q: %v", location, err)
+		return Conditions{}, err
 	}
-
-	// The formatForecast method returns its own error.
-	return c.formatForecast(resp)
+	w.IsStale = stale
+	return w, nil
 }
 
-// formatForecast accepts weather conditions and returns formatted text.
-func (c *Client) formatForecast(w conditions) (string, error) {
-	tempUnit := tempUnitName[c.tempUnit]
-	speedUnit := speedUnitName[c.speedUnit]
-
-	var temperature string
-	if w.temperature != nil {
-		temperature = fmt.Sprintf(", temp %.1f%v", c.ConvertTemp(*w.temperature), tempUnit)
+// queryForecast accepts an OpenWeatherMap.org `/2.5/forecast` URL and returns
+// the decoded weather API response, and whether it is stale.
+func (c Client) queryForecast(ctx context.Context, url string) (owmResponse, bool, error) {
+	var ar owmResponse
+	stale, err := c.query(ctx, url, func(data []byte) error {
+		var err error
+		ar, err = c.decodeForecast(data)
+		return err
+	})
+	if err != nil {
+		return owmResponse{}, false, err
 	}
+	return ar, stale, nil
+}
 
-	var feelsLike string
-	if w.feelsLike != nil {
-		feelsLike = fmt.Sprintf(", feels like %.1f%v", c.ConvertTemp(*w.feelsLike), tempUnit)
+// query fetches an OpenWeatherMap.org URL and passes the response body to
+// decode, logging the query. If the weather client is configured to return
+// stale results, an eligible error is replaced by decoding the last good
+// response body for the same URL, and true is returned.
+func (c Client) query(ctx context.Context, url string, decode func([]byte) error) (bool, error) {
+	start := time.Now()
+	data, status, err := c.fetchStatus(ctx, url)
+	if err == nil {
+		err = decode(data)
 	}
-
-	var humidity string
-	if w.humidity != nil {
-		humidity = fmt.Sprintf(", humidity %.1f%%", *w.humidity)
+	data, stale, err := c.staleOnError(url, data, err)
+	if stale {
+		err = decode(data)
 	}
+	c.logQuery(ctx, url, time.Since(start), status, stale, err)
+	return stale, err
+}
 
-	var wind string
-	if w.windSpeed != nil {
-		wind = fmt.Sprintf(", wind %.1f %v", c.ConvertSpeed(*w.windSpeed), speedUnit)
+// logQuery emits a structured log record for a weather API query, if the
+// weather client has a logger. The API key is redacted from the URL, and
+// cacheHit is true when stale conditions were returned instead of an error.
+func (c Client) logQuery(ctx context.Context, url string, duration time.Duration, status int, cacheHit bool, err error) {
+	if c.logger == nil {
+		return
 	}
 
-	forecast := fmt.Sprintf("%s%s%s%s%s",
-		*w.description, temperature, feelsLike, humidity, wind)
-
-	return forecast, nil
+	attrs := []slog.Attr{
+		slog.String("url", c.redactAPIKey(url)),
+		slog.Duration("duration", duration),
+		slog.Int("status_code", status),
+		slog.Bool("cache_hit", cacheHit),
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", c.redactAPIKey(err.Error())))
+	}
+	c.logger.LogAttrs(ctx, level, "weather API query", attrs...)
 }
 
-// RunCLI accepts CLI arguments, and output and error io.Writers,
-// and supplies the forecast for the location in `args`.
-func RunCLI(args []string, output, errOutput io.Writer) error {
-	apiKey := os.Getenv("OPENWEATHERMAP_API_KEY")
-	if apiKey == "" {
-		return fmt.Errorf(`Please set the OPENWEATHERMAP_API_KEY environment variable to an OpenWeatherMap API key.
-		To obtain an API key, see https://home.openweathermap.org/api_keys`)
+// redactAPIKey returns s with the API key of the weather client replaced.
+func (c Client) redactAPIKey(s string) string {
+	if c.APIKey == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, c.APIKey, "REDACTED")
+}
+
+// ParseForecastJSON accepts JSON from the OpenWeatherMap.org API
+// `/2.5/forecast`, and returns weather conditions for the first forecast entry,
+// in the Kelvin and meters/sec units used by the weather API.
+func ParseForecastJSON(data []byte) (Conditions, error) {
+	return Client{selectionStrategy: SelectionFirst}.parseForecast(data)
+}
+
+// parseForecast accepts JSON from the OpenWeatherMap.org API `/2.5/forecast`,
+// and returns weather conditions for the entry chosen by the selection
+// strategy of the weather client.
+func (c Client) parseForecast(data []byte) (Conditions, error) {
+	ar, err := c.decodeForecast(data)
+	if err != nil {
+		return Conditions{}, err
+	}
+
+	i := c.selectEntry(ar)
+	w, err := ar.conditions(i, c.primaryCondition)
+	if err != nil {
+		return Conditions{}, err
+	}
+	err = c.checkHumidity(w)
+	if err != nil {
+		return Conditions{}, err
+	}
+
+	if c.selectionStrategy == SelectionAggregateMinMax {
+		minMax := new([2]float64)
+		for _, e := range ar.List {
+			t := e.Main.Temp
+			if !t.present {
+				continue
+			}
+			if w.TempMin == nil || t.value < *w.TempMin {
+				w.TempMin = t.store(&minMax[0])
+			}
+			if w.TempMax == nil || t.value > *w.TempMax {
+				w.TempMax = t.store(&minMax[1])
+			}
+		}
+	}
+
+	return w, nil
+}
+
+// decodeForecast accepts JSON from the OpenWeatherMap.org API `/2.5/forecast`,
+// and returns a weather API response with at least one `List` entry. Unknown
+// fields are an error if the weather client disallows them.
+func (c Client) decodeForecast(data []byte) (owmResponse, error) {
+	var ar owmResponse
+	err := c.decodeJSON(data, &ar)
+	if err != nil {
+		return owmResponse{}, err
+	}
+	err = checkCod(ar.Cod, ar.Message)
+	if err != nil {
+		return owmResponse{}, err
+	}
+
+	if len(ar.List) == 0 {
+		return owmResponse{}, fmt.Errorf("unexpected empty `List` from weather API: %+v", ar)
+	}
+	return ar, nil
+}
+
+// decodeJSON decodes a weather API response body into v. Unknown fields are
+// an error if the weather client disallows them.
+func (c Client) decodeJSON(data []byte, v interface{}) error {
+	if !c.disallowUnknownFields {
+		return json.Unmarshal(data, v)
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	return d.Decode(v)
+}
+
+// checkCod returns an error if the `cod` of a weather API response is an
+// error, which the weather API can report despite an HTTP 200 status.
+func checkCod(cod, message owmString) error {
+	switch cod {
+	case "", "200":
+		return nil
+	case "404":
+		return fmt.Errorf("%w: %s", ErrLocationNotFound, message)
+	}
+	return checkAPIKeyError(&APIError{StatusCode: http.StatusOK, Status: "200 OK", Body: newOWMErrorBody(cod, message)})
+}
+
+// conditions returns weather conditions for the `List` entry at index i of a
+// weather API response, in the Kelvin and meters/sec units used by the
+// weather API. The time of the conditions is in the time zone of the city,
+// and its description is of the condition chosen by primaryCondition, one of
+// the PrimaryCondition... strategies.
+func (ar owmResponse) conditions(i int, primaryCondition string) (Conditions, error) {
+	entry := ar.List[i]
+	if len(entry.Weather) == 0 {
+		return Conditions{}, fmt.Errorf("unexpected empty List[%d].Weather from weather API: %+v", i, ar)
+	}
+
+	// The fields of Conditions point into this single allocation.
+	values := new(struct {
+		temperature, feelsLike, humidity, windSpeed, windDirection, pop, latitude, longitude float64
+	})
+
+	primary := entry.Weather[primaryWeather(entry.Weather, primaryCondition)]
+	w := Conditions{
+		Description:              primary.Description,
+		ShortDescription:         primary.Main,
+		Temperature:              entry.Main.Temp.store(&values.temperature),
+		FeelsLike:                entry.Main.Feels_like.store(&values.feelsLike),
+		Humidity:                 entry.Main.Humidity.store(&values.humidity),
+		WindSpeed:                entry.Wind.Speed.store(&values.windSpeed),
+		WindDirection:            entry.Wind.Deg.store(&values.windDirection),
+		PrecipitationProbability: entry.Pop.store(&values.pop),
+		Latitude:                 ar.City.Coord.Lat.store(&values.latitude),
+		Longitude:                ar.City.Coord.Lon.store(&values.longitude),
+		TempUnit:                 TempUnitKelvin,
+		SpeedUnit:                SpeedUnitMeters,
+	}
+	// The weather API returns probability as a fraction.
+	if w.PrecipitationProbability != nil {
+		*w.PrecipitationProbability *= 100
+	}
+	if entry.Dt != 0 {
+		w.Time = time.Unix(entry.Dt, 0).In(ar.location())
+	}
+	return w, nil
+}
+
+// checkHumidity returns an error if the humidity of weather conditions is
+// outside of 0 to 100 percent. If the weather client clamps humidity, the
+// humidity is instead changed in place to the nearest plausible value.
+func (c Client) checkHumidity(w Conditions) error {
+	if w.Humidity == nil || (*w.Humidity >= 0 && *w.Humidity <= 100) {
+		return nil
+	}
+	if !c.clampHumidity {
+		return fmt.Errorf("implausible humidity %v%% from weather API, humidity should be from 0 to 100 percent", *w.Humidity)
+	}
+	if *w.Humidity < 0 {
+		*w.Humidity = 0
+	} else {
+		*w.Humidity = 100
+	}
+	return nil
+}
+
+// location returns the time zone of the city in a weather API response, or UTC
+// if the time zone is unknown.
+func (ar owmResponse) location() *time.Location {
+	return zone(ar.City.Timezone)
+}
+
+// zone returns a time zone for an offset in seconds from UTC, as returned by
+// the weather API, or UTC if the offset is 0.
+func zone(offset int) *time.Location {
+	if offset == 0 {
+		return time.UTC
+	}
+	return time.FixedZone("", offset)
+}
+
+// selectEntry returns the index of the `List` entry to use from a weather API
+// response, according to the selection strategy of the weather client.
+// The response `List` must not be empty.
+func (c Client) selectEntry(ar owmResponse) int {
+	if c.selectionStrategy != SelectionNearestNow {
+		return 0
+	}
+
+	now := time.Now().Unix()
+	var nearest int
+	for i, e := range ar.List {
+		if abs(e.Dt-now) < abs(ar.List[nearest].Dt-now) {
+			nearest = i
+		}
+	}
+	return nearest
+}
+
+// abs returns the absolute value of an int64.
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Weather API endpoints. The forecast endpoint is the default APIURI.
+const (
+	forecastURI       = "/data/2.5/forecast"
+	currentWeatherURI = "/data/2.5/weather"
+	geocodingURI      = "/geo/1.0/direct"
+)
+
+// uncountedURIs stores the weather API endpoints which do not accept the
+// `cnt` parameter.
+var uncountedURIs = map[string]bool{
+	currentWeatherURI: true,
+	geocodingURI:      true,
+}
+
+// formAPIUrl returns a forecast API URL which queries using the specified
+// parameter and value, such as q=London, for count forecast entries. An error
+// is returned if the APIURI of the weather client is not a valid URL path.
+func (c Client) formAPIUrl(param, value string, count int) (string, error) {
+	if !strings.HasPrefix(c.APIURI, "/") {
+		return "", fmt.Errorf("API URI %q is invalid, it must begin with a slash", c.APIURI)
+	}
+	u := c.formEndpointURL(c.APIURI, param, value, count)
+	_, err := url.Parse(u)
+	if err != nil {
+		return "", fmt.Errorf("API URI %q is invalid: %w", c.APIURI, err)
+	}
+	return u, nil
+}
+
+// formEndpointURL returns a URL for the specified weather API endpoint, which
+// queries using the specified parameter and value. The `cnt` parameter is set
+// to count, except for endpoints which do not accept it.
+func (c Client) formEndpointURL(uri, param, value string, count int) string {
+	u := fmt.Sprintf("%s%s/?%s=%s&%s=%s", c.APIHost, uri, c.paramName(param), url.QueryEscape(value), c.paramName("appid"), c.APIKey)
+	if uncountedURIs[uri] {
+		return u
+	}
+	return u + "&" + c.paramName("cnt") + "=" + strconv.Itoa(count)
+}
+
+// paramName returns the name of a weather API query parameter, as renamed by
+// WithParamNames.
+func (c Client) paramName(param string) string {
+	if name, ok := c.paramNames[param]; ok {
+		return name
+	}
+	return param
+}
+
+// Ping verifies that the weather API can be reached and accepts the API key of
+// the weather client, by requesting a forecast for a well-known location. The
+// request uses the HEAD method, to avoid transferring the forecast, and falls
+// back to GET if the weather API responds that HEAD is not allowed.
+func (c *Client) Ping() error {
+	// This does not use queryAPI, so stale conditions can not hide an error.
+	apiURL, err := c.formAPIUrl("q", "London", 1)
+	if err != nil {
+		return fmt.Errorf("Error pinging weather API: %w", err)
+	}
+
+	// A HEAD request avoids transferring a forecast, falling back to GET if
+	// the weather API does not allow HEAD.
+	_, status, err := c.send(context.Background(), http.MethodHead, apiURL, nil)
+	if status == http.StatusMethodNotAllowed {
+		var data []byte
+		data, err = c.fetch(context.Background(), apiURL)
+		if err == nil {
+			_, err = c.parseForecast(data)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("Error pinging weather API: %w", err)
+	}
+	return nil
+}
+
+// Forecast accepts a location and returns a forecast, as a single line
+// without a trailing newline. Add a newline when writing forecasts one after
+// another, such as using fmt.Println.
+func (c *Client) Forecast(location string) (string, error) {
+	return c.ForecastWithContext(context.Background(), location)
+}
+
+// ForecastWithContext is Forecast, with a context which can cancel the weather
+// API request. Like Forecast, the forecast does not end with a newline. See
+// ForecastConditionsWithContext for the errors returned when a request is
+// cancelled or times out.
+func (c *Client) ForecastWithContext(ctx context.Context, location string) (string, error) {
+	_, forecast, err := c.forecastWithConditions(ctx, location)
+	return forecast, err
+}
+
+// forecastWithConditions is ForecastWithContext, also returning the
+// conditions used for the forecast.
+func (c *Client) forecastWithConditions(ctx context.Context, location string) (Conditions, string, error) {
+	w, err := c.ForecastConditionsWithContext(ctx, location)
+	if err != nil {
+		return Conditions{}, "", err
+	}
+
+	forecast, err := c.formatForecast(w)
+	if err != nil {
+		return Conditions{}, "", err
+	}
+	if _, ok := c.locationAliases[location]; ok {
+		forecast = location + ": " + forecast
+	}
+	return w, forecast, nil
+}
+
+// ForecastJSON accepts a location and returns forecast conditions as compact
+// JSON, in the units set in the weather client. See Conditions.JSON.
+func (c *Client) ForecastJSON(location string) ([]byte, error) {
+	_, data, err := c.forecastJSON(context.Background(), location, false)
+	return data, err
+}
+
+// forecastJSON is ForecastJSON, with a context which can cancel the weather
+// API request, also returning the forecast conditions, and returning indented
+// JSON for people to read if pretty is true.
+func (c *Client) forecastJSON(ctx context.Context, location string, pretty bool) (Conditions, []byte, error) {
+	w, err := c.ForecastConditionsWithContext(ctx, location)
+	if err != nil {
+		return Conditions{}, nil, err
+	}
+	var data []byte
+	if pretty {
+		data, err = json.MarshalIndent(w, "", "  ")
+	} else {
+		data, err = w.JSON()
+	}
+	return w, data, err
+}
+
+// ForecastYAML accepts a location and returns forecast conditions as YAML, in
+// the units set in the weather client. Keys match those of ForecastJSON, fields
+// which are not present are omitted, and units are represented by name. See
+// Conditions.MarshalYAML.
+func (c *Client) ForecastYAML(location string) ([]byte, error) {
+	_, data, err := c.forecastYAML(context.Background(), location)
+	return data, err
+}
+
+// forecastYAML is ForecastYAML, with a context which can cancel the weather
+// API request, also returning the forecast conditions.
+func (c *Client) forecastYAML(ctx context.Context, location string) (Conditions, []byte, error) {
+	w, err := c.ForecastConditionsWithContext(ctx, location)
+	if err != nil {
+		return Conditions{}, nil, err
+	}
+	data, err := yaml.Marshal(w)
+	return w, data, err
+}
+
+// resolveLocation returns the location to query from the weather API for a
+// location alias, or else the location, normalized using NormalizeLocation.
+func (c *Client) resolveLocation(location string) string {
+	if resolved, ok := c.locationAliases[location]; ok {
+		location = resolved
+	}
+	return NormalizeLocation(location)
+}
+
+// NormalizeLocation returns a location in a consistent form, so that
+// variations such as "New York ,us" and "New  York,US" are queried and cached
+// the same way. Each comma-separated part is trimmed and has internal
+// whitespace collapsed to single spaces, and trailing two-letter state and
+// country codes after the first part are upper-cased. Names are otherwise left
+// as written, as changing their case would break names such as McAllen or
+// DeKalb.
+func NormalizeLocation(loc string) string {
+	parts := strings.Split(loc, ",")
+	for i, part := range parts {
+		parts[i] = strings.Join(strings.Fields(part), " ")
+	}
+	for i := len(parts) - 1; i > 0 && isLocationCode(parts[i]); i-- {
+		parts[i] = strings.ToUpper(parts[i])
+	}
+	return strings.Join(parts, ",")
+}
+
+// isLocationCode returns true if s is two ASCII letters, such as a state or
+// country code.
+func isLocationCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// ForecastConditions accepts a location and returns forecast conditions in the
+// units set in the weather client.
+func (c *Client) ForecastConditions(location string) (Conditions, error) {
+	return c.ForecastConditionsWithContext(context.Background(), location)
+}
+
+// ForecastConditionsWithContext is ForecastConditions, with a context which
+// can cancel the weather API request. A cancelled request returns a
+// ContextCanceledError, a request whose context deadline is exceeded returns
+// a DeadlineExceededError, and a request which exceeds the timeout of the HTTP
+// client returns a ClientTimeoutError; use errors.As to check for these.
+func (c *Client) ForecastConditionsWithContext(ctx context.Context, location string) (Conditions, error) {
+	apiURL, err := c.formAPIUrl("q", c.resolveLocation(location), 1)
+	if err != nil {
+		return Conditions{}, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+
+	resp, err := c.queryAPI(ctx, apiURL)
+	if err != nil {
+		return Conditions{}, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+	return c.prepareConditions(resp), nil
+}
+
+// ForecastDual accepts a location and returns forecast conditions in both
+// metric (Celsius and meters/sec) and imperial (Fahrenheit and miles/hour)
+// units, for displays which show both. The weather API is queried once, and
+// both are converted from the values it returned, regardless of the units set
+// in the weather client.
+func (c *Client) ForecastDual(location string) (metric, imperial Conditions, err error) {
+	apiURL, err := c.formAPIUrl("q", c.resolveLocation(location), 1)
+	if err != nil {
+		return Conditions{}, Conditions{}, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+
+	resp, err := c.queryAPI(context.Background(), apiURL)
+	if err != nil {
+		return Conditions{}, Conditions{}, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+	metric = c.prepareConditionsIn(resp, TempUnitCelsius, SpeedUnitMeters)
+	imperial = c.prepareConditionsIn(resp, TempUnitFahrenheit, SpeedUnitMiles)
+	return metric, imperial, nil
+}
+
+// DaylightHours accepts a location and returns the duration of daylight, from
+// sunrise to sunset, as reported by the weather API. An error is returned if
+// the weather API does not include sunrise or sunset, which it omits or
+// reports as zero during polar day or night. Otherwise the duration is within
+// zero to 24 hours.
+func (c *Client) DaylightHours(location string) (time.Duration, error) {
+	apiURL, err := c.formAPIUrl("q", c.resolveLocation(location), 1)
+	if err != nil {
+		return 0, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+
+	ar, _, err := c.queryForecast(context.Background(), apiURL)
+	if err != nil {
+		return 0, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+
+	sunrise, sunset := ar.City.Sunrise, ar.City.Sunset
+	if !sunrise.present || !sunset.present || sunrise.value == 0 || sunset.value == 0 {
+		return 0, fmt.Errorf("the weather API did not return sunrise and sunset for location %q, which can happen during polar day or night", location)
+	}
+
+	d := time.Duration(sunset.value-sunrise.value) * time.Second
+	// Sunrise and sunset can be reported for different days near the poles.
+	if d < 0 {
+		d += 24 * time.Hour
+	}
+	if d < 0 {
+		d = 0
+	}
+	if d > 24*time.Hour {
+		d = 24 * time.Hour
+	}
+	return d, nil
+}
+
+// WebURL accepts a location and returns a link to its forecast on the
+// OpenWeatherMap.org website, such as https://openweathermap.org/city/5119226,
+// to share with people. If the weather API does not return the ID of the
+// city, a link to the weather map at its coordinates is returned instead.
+func (c *Client) WebURL(location string) (string, error) {
+	apiURL, err := c.formAPIUrl("q", c.resolveLocation(location), 1)
+	if err != nil {
+		return "", fmt.Errorf("Error querying weather API for location %q: %w", location, err)
 	}
 
+	ar, _, err := c.queryForecast(context.Background(), apiURL)
+	if err != nil {
+		return "", fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+
+	city := ar.City
+	switch {
+	case city.ID != 0:
+		return fmt.Sprintf("https://openweathermap.org/city/%d", city.ID), nil
+	case city.Coord.Lat.present && city.Coord.Lon.present:
+		return fmt.Sprintf("https://openweathermap.org/weathermap?zoom=10&lat=%s&lon=%s",
+			strconv.FormatFloat(city.Coord.Lat.value, 'f', -1, 64), strconv.FormatFloat(city.Coord.Lon.value, 'f', -1, 64)), nil
+	}
+	return "", fmt.Errorf("the weather API did not return a city ID or coordinates for location %q", location)
+}
+
+// maxForecastCount is the most forecast entries returned by the weather API,
+// covering five days in three hour intervals.
+const maxForecastCount = 40
+
+// HourlyForecast accepts a location and returns conditions for up to count
+// upcoming forecast entries, in the units set in the weather client. The
+// weather API forecasts in three hour intervals, and count can be at most 40.
+func (c *Client) HourlyForecast(location string, count int) ([]Conditions, error) {
+	if count < 1 || count > maxForecastCount {
+		return nil, fmt.Errorf("forecast count %d is out of range, please specify a count from 1 to %d", count, maxForecastCount)
+	}
+
+	apiURL, err := c.formAPIUrl("q", c.resolveLocation(location), count)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+
+	ar, stale, err := c.queryForecast(context.Background(), apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+	}
+
+	forecast := make([]Conditions, len(ar.List))
+	for i := range ar.List {
+		w, err := ar.conditions(i, c.primaryCondition)
+		if err == nil {
+			err = c.checkHumidity(w)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error querying weather API for location %q: %w", location, err)
+		}
+		w.IsStale = stale
+		forecast[i] = c.prepareConditions(w)
+	}
+	return forecast, nil
+}
+
+// TomorrowForecast accepts a location and returns the forecast conditions
+// nearest to noon tomorrow, in the time zone of the location and the units set
+// in the weather client. Tomorrow is the day after the first forecast entry,
+// which is the current time. An error is returned if the forecast does not
+// reach tomorrow.
+func (c *Client) TomorrowForecast(location string) (Conditions, error) {
+	forecast, err := c.HourlyForecast(location, maxForecastCount)
+	if err != nil {
+		return Conditions{}, err
+	}
+	if len(forecast) == 0 {
+		return Conditions{}, fmt.Errorf("the weather API did not return a forecast for location %q", location)
+	}
+
+	first := forecast[0].Time
+	y, m, d := first.Date()
+	noon := time.Date(y, m, d+1, 12, 0, 0, 0, first.Location())
+	ty, tm, td := noon.Date()
+
+	nearest := -1
+	for i, w := range forecast {
+		wy, wm, wd := w.Time.Date()
+		if wy != ty || wm != tm || wd != td {
+			continue
+		}
+		if nearest == -1 || absDuration(w.Time.Sub(noon)) < absDuration(forecast[nearest].Time.Sub(noon)) {
+			nearest = i
+		}
+	}
+	if nearest == -1 {
+		return Conditions{}, fmt.Errorf("the forecast for location %q does not reach tomorrow, %s", location, noon.Format("January 2"))
+	}
+	return forecast[nearest], nil
+}
+
+// absDuration returns the absolute value of a time.Duration.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// sparkBlocks stores the characters of a sparkline, from lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// TemperatureSparkline accepts a location and returns a sparkline of the
+// temperatures of the next n forecast entries, such as "▃▂▁▂▅█▇▄," scaled so
+// the lowest temperature is ▁ and the highest is █. An entry without a
+// temperature is a space. The weather API forecasts in three hour intervals,
+// and n can be at most 40.
+func (c *Client) TemperatureSparkline(location string, n int) (string, error) {
+	forecast, err := c.HourlyForecast(location, n)
+	if err != nil {
+		return "", err
+	}
+	if len(forecast) > n {
+		forecast = forecast[:n]
+	}
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, w := range forecast {
+		if w.Temperature != nil {
+			min = math.Min(min, *w.Temperature)
+			max = math.Max(max, *w.Temperature)
+		}
+	}
+
+	spark := make([]rune, len(forecast))
+	for i, w := range forecast {
+		switch {
+		case w.Temperature == nil:
+			spark[i] = ' '
+		case max == min:
+			spark[i] = sparkBlocks[0]
+		default:
+			scaled := (*w.Temperature - min) / (max - min) * float64(len(sparkBlocks)-1)
+			spark[i] = sparkBlocks[int(math.Round(scaled))]
+		}
+	}
+	return string(spark), nil
+}
+
+// ForecastByCityID accepts an OpenWeatherMap.org city ID and returns a
+// forecast. Querying by ID avoids ambiguous location names, such as Paris, TX
+// versus Paris, France.
+//
+// The ID of a city is at the end of its URL on openweathermap.org, for
+// example 2643743 in https://openweathermap.org/city/2643743 for London. A
+// list of all city IDs is available in city.list.json.gz at
+// https://bulk.openweathermap.org/sample/
+func (c *Client) ForecastByCityID(id int) (string, error) {
+	apiURL, err := c.formAPIUrl("id", strconv.Itoa(id), 1)
+	if err != nil {
+		return "", fmt.Errorf("Error querying weather API for %s: %w", cityLabel(id), err)
+	}
+
+	resp, err := c.queryAPI(context.Background(), apiURL)
+	if err != nil {
+		return "", fmt.Errorf("Error querying weather API for %s: %w", cityLabel(id), err)
+	}
+
+	// The formatForecast method returns its own error.
+	return c.formatForecast(c.prepareConditions(resp))
+}
+
+// prepareConditions returns a copy of weather conditions converted to the
+// units set in the weather client, with the description shortened to the
+// configured maximum length.
+func (c *Client) prepareConditions(w Conditions) Conditions {
+	return c.prepareConditionsIn(w, c.tempUnit, c.speedUnit)
+}
+
+// prepareConditionsIn is prepareConditions, converting to the specified units
+// instead of those set in the weather client.
+func (c *Client) prepareConditionsIn(w Conditions, tempUnit TempUnit, speedUnit SpeedUnit) Conditions {
+	w = w.convert(tempUnit, speedUnit)
+	if w.Description != nil {
+		d := c.truncateDescription(*w.Description)
+		w.Description = &d
+	}
+	if w.ShortDescription != nil {
+		d := c.truncateDescription(*w.ShortDescription)
+		w.ShortDescription = &d
+	}
+	return w
+}
+
+// noWeatherData is the forecast for weather conditions without any usable
+// fields.
+const noWeatherData = "no weather data available"
+
+// formatForecast accepts weather conditions and returns formatted text,
+// without a trailing newline, which callers add when writing output. Fields
+// are joined using the separator configured in the weather client.
+// Conditions with no usable fields, such as from a malformed weather API
+// response, are formatted as noWeatherData, which is an error if the weather
+// client formats strictly.
+func (c *Client) formatForecast(w Conditions) (string, error) {
+	tempUnit := tempUnitName[w.TempUnit]
+	speedUnit := speedUnitName[w.SpeedUnit]
+
+	description := w.Description
+	if c.shortDescription && w.ShortDescription != nil {
+		description = w.ShortDescription
+	}
+	var fields []string
+	if description != nil {
+		fields = append(fields, *description)
+	}
+
+	switch {
+	case w.TempMin != nil && w.TempMax != nil:
+		fields = append(fields, "temp "+c.formatTemps(w.TempUnit, *w.TempMin, *w.TempMax))
+	case w.Temperature != nil:
+		fields = append(fields, "temp "+c.formatTemps(w.TempUnit, *w.Temperature))
+	}
+
+	if w.FeelsLike != nil {
+		fields = append(fields, "feels like "+c.formatTemps(w.TempUnit, *w.FeelsLike))
+	}
+
+	if w.Humidity != nil {
+		fields = append(fields, fmt.Sprintf("humidity %.1f%%", *w.Humidity))
+	}
+
+	if w.WindSpeed != nil {
+		wind := fmt.Sprintf("%.1f", *w.WindSpeed)
+		// Negative zero is also considered calm.
+		switch {
+		case c.calmWindLabel && (wind == "0.0" || wind == "-0.0"):
+			fields = append(fields, "wind calm")
+		case c.windStyle == WindStyleCompact:
+			deg := math.NaN()
+			if w.WindDirection != nil {
+				deg = *w.WindDirection
+			}
+			fields = append(fields, "wind "+c.FormatWind(*w.WindSpeed, deg))
+		default:
+			fields = append(fields, fmt.Sprintf("wind %s %v", wind, speedUnit))
+		}
+	}
+
+	if len(fields) == 0 {
+		if c.strictFormatting {
+			return noWeatherData, ErrNoWeatherData
+		}
+		return noWeatherData, nil
+	}
+
+	forecast := strings.Join(fields, c.fieldSeparator)
+
+	// The resolved coordinates can differ from what was intended when querying
+	// a location by name.
+	if c.showCoordinates && w.Latitude != nil && w.Longitude != nil {
+		forecast += fmt.Sprintf(" (%.2f, %.2f)", *w.Latitude, *w.Longitude)
+	}
+
+	if c.unitsFooter {
+		forecast += fmt.Sprintf("\n[units: temp=%s, wind=%s]", strings.TrimSpace(tempUnit), speedUnit)
+	}
+
+	return forecast, nil
+}
+
+// formatTemps returns one temperature, or a range of temperatures joined by
+// "to," in the unit u followed by its name. If the weather client displays
+// dual temperatures, they are returned in Celsius then Fahrenheit, such as
+// "12.9 ºC / 55.4 ºF."
+func (c *Client) formatTemps(u TempUnit, temps ...float64) string {
+	units := []TempUnit{u}
+	if c.dualTempDisplay {
+		units = []TempUnit{TempUnitCelsius, TempUnitFahrenheit}
+	}
+
+	parts := make([]string, len(units))
+	for i, to := range units {
+		values := make([]string, len(temps))
+		for j, t := range temps {
+			if to != u {
+				t = tempFromKelvin(tempToKelvin(t, u), to)
+			}
+			values[j] = fmt.Sprintf("%.1f", t)
+		}
+		parts[i] = strings.Join(values, " to ") + tempUnitName[to]
+	}
+	return strings.Join(parts, " / ")
+}
+
+// FormatWind returns wind in a compact aviation style, such as "240@12mph,"
+// with the direction in degrees padded to three digits, and the speed in the
+// unit of the weather client rounded to a whole number. If the direction is
+// unknown, signified by a negative number or math.NaN(), only the speed and
+// unit are returned, such as "12mph."
+func (c *Client) FormatWind(speed, deg float64) string {
+	s := fmt.Sprintf("%.0f%s", speed, speedUnitName[c.speedUnit])
+	if math.IsNaN(deg) || deg < 0 {
+		return s
+	}
+	return fmt.Sprintf("%03.0f@%s", deg, s)
+}
+
+// AppendCSV gets forecast conditions for a location, and writes them to w as
+// a CSV row, preceded by a header row if writeHeader is true. The first
+// column, timestamp, is the current time in RFC3339 format, followed by the
+// columns of Conditions.ToCSVRow. This is suitable for periodically
+// appending readings to a log file.
+func (c *Client) AppendCSV(w io.Writer, location string, writeHeader bool) error {
+	conditions, err := c.ForecastConditions(location)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if writeHeader {
+		err = cw.Write(append([]string{"timestamp"}, csvHeader...))
+		if err != nil {
+			return err
+		}
+	}
+	err = cw.Write(append([]string{time.Now().Format(time.RFC3339)}, conditions.csvFields()...))
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// accuracyReport stores the fields sent by ReportAccuracy.
+type accuracyReport struct {
+	Location          string     `json:"location"`
+	ForecastedAt      time.Time  `json:"forecasted_at"`
+	Forecast          Conditions `json:"forecast"`
+	ActualDescription string     `json:"actual_description"`
+	APIURI            string     `json:"api_uri"`
+}
+
+// ReportAccuracy sends the forecast conditions for a location that was
+// forecast at a given time, along with the actual weather description, to the
+// endpoint configured using WithFeedbackURL. This is opt-in, and the API key
+// is never included in the report. Unlike the proposed location, forecast time
+// and actual description, the report also takes the forecast conditions, as
+// the client does not store past forecasts to compare with the actual weather.
+func (c *Client) ReportAccuracy(location string, forecastedAt time.Time, forecast Conditions, actualDescription string) error {
+	return c.ReportAccuracyWithContext(context.Background(), location, forecastedAt, forecast, actualDescription)
+}
+
+// ReportAccuracyWithContext is ReportAccuracy, with a context which can cancel
+// the request to the feedback endpoint. The request uses the HTTP client,
+// middleware, and maximum response size of the weather client.
+func (c *Client) ReportAccuracyWithContext(ctx context.Context, location string, forecastedAt time.Time, forecast Conditions, actualDescription string) error {
+	if c.feedbackURL == "" {
+		return fmt.Errorf("accuracy reporting is disabled, please set a feedback endpoint using the WithFeedbackURL option")
+	}
+
+	body, err := json.Marshal(accuracyReport{
+		Location:          location,
+		ForecastedAt:      forecastedAt,
+		Forecast:          forecast,
+		ActualDescription: actualDescription,
+		APIURI:            c.APIURI,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.send(ctx, http.MethodPost, c.feedbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Error reporting accuracy for location %q: %w", location, err)
+	}
+	return nil
+}
+
+// truncateDescription shortens a weather description to the maximum length
+// configured in the weather client, counting runes instead of bytes so
+// multi-byte characters are not split.
+func (c *Client) truncateDescription(d string) string {
+	r := []rune(d)
+	if c.maxDescriptionLength == 0 || len(r) <= c.maxDescriptionLength {
+		return d
+	}
+	// The ellipsis counts towards the maximum length.
+	return string(r[:c.maxDescriptionLength-1]) + "…"
+}
+
+// RunCLI accepts CLI arguments, an input io.Reader, and output and error
+// io.Writers, and supplies the forecast for the location in `args`. If the
+// location is "-", newline-delimited locations are read from input.
+func RunCLI(args []string, input io.Reader, output, errOutput io.Writer) error {
 	fs := flag.NewFlagSet("weather-caster", flag.ExitOnError)
 	fs.SetOutput(errOutput)
 	cliLocation := fs.String("l", "", `The location for which you want a weather forecast. Also specified via the WEATHERCASTER_LOCATION environment variable.
@@ -305,16 +1839,33 @@ func RunCLI(args []string, output, errOutput io.Writer) error {
 	"LocationName" (for well-known locations, such as London)
 	"CitynName,StateName,CountryCode"
 	For example: "Great Neck Plaza,NY,US"
+	Use "-" to read newline-delimited locations from standard input.
 `)
 
 	cliSpeedUnit := fs.String("s", "", "Unit of measure to use when displaying wind speed (miles or meters). Also specified via the WEATHERCASTER_SPEED_UNIT environment variable. The default is miles.")
 	cliTempUnit := fs.String("t", "", "Unit of measure to use when displaying temperature (c for Celsius, f for Fahrenheit, or k for kelvin). Also specified via the WEATHERCASTER_TEMP_UNIT environment variable. The default is Fahrenheit.")
+	cliVersion := fs.Bool("version", false, "Print the version of this client and exit.")
+	cliJSON := fs.Bool("json", false, "Output forecast conditions as compact JSON, such as for piping to other tools.")
+	cliJSONPretty := fs.Bool("json-pretty", false, "Output forecast conditions as indented JSON, for reading.")
+	cliYAML := fs.Bool("yaml", false, "Output forecast conditions as YAML, such as for configuration files.")
+	cliVerbose := fs.Bool("v", false, "Verbose: log weather API queries and how long each forecast took to standard error.")
+	cliConvertKelvin := fs.Bool("convert-kelvin", false, "Convert Kelvin temperatures to the unit specified by -t, instead of getting a forecast. Temperatures are read from command-line arguments, or newline-delimited from standard input if there are no arguments. No API key is required.")
+	fs.Usage = func() {
+		fmt.Fprintf(errOutput, "Usage of %s:\n", fs.Name())
+		fs.PrintDefaults()
+		fmt.Fprintln(errOutput, "\nSet the WEATHERCASTER_API_HOST environment variable, such as to http://localhost:8080, to query a weather API other than https://api.openweathermap.org.")
+	}
 
 	err := fs.Parse(args)
 	if err != nil {
 		return err
 	}
 
+	if *cliVersion {
+		fmt.Fprintf(output, "weathercaster version %s\n", Version())
+		return nil
+	}
+
 	// Use environment variables if command-line flags were not specified.
 	if *cliSpeedUnit == "" {
 		*cliSpeedUnit = os.Getenv("WEATHERCASTER_SPEED_UNIT")
@@ -326,10 +1877,6 @@ func RunCLI(args []string, output, errOutput io.Writer) error {
 		*cliLocation = os.Getenv("WEATHERCASTER_LOCATION")
 	}
 
-	if *cliLocation == "" {
-		return fmt.Errorf("Please specify a location using either the -l command-line flag, or by setting the WEATHERCASTER_LOCATION environment variable.")
-	}
-
 	speedUnit, err := ProcessCLISpeedUnit(*cliSpeedUnit)
 	if err != nil {
 		return err
@@ -340,20 +1887,264 @@ func RunCLI(args []string, output, errOutput io.Writer) error {
 		return err
 	}
 
-	wc, err := NewClient(apiKey, WithSpeedUnit(speedUnit), WithTempUnit(tempUnit))
+	// Units specified individually take precedence over the measurement
+	// system.
+	if measurement := os.Getenv("WEATHERCASTER_MEASUREMENT"); measurement != "" {
+		measurementTemp, measurementSpeed, err := ProcessCLIMeasurement(measurement)
+		if err != nil {
+			return err
+		}
+		if *cliTempUnit == "" {
+			tempUnit = measurementTemp
+		}
+		if *cliSpeedUnit == "" {
+			speedUnit = measurementSpeed
+		}
+	}
+
+	if *cliYAML && (*cliJSON || *cliJSONPretty) {
+		return fmt.Errorf("Please specify only one of the -yaml or -json output formats.")
+	}
+
+	if *cliConvertKelvin {
+		return convertKelvin(fs.Args(), tempUnit, input, output)
+	}
+
+	apiKey := os.Getenv("OPENWEATHERMAP_API_KEY")
+	if apiKey == "" {
+		return fmt.Errorf(`Please set the OPENWEATHERMAP_API_KEY environment variable to an OpenWeatherMap API key.
+		To obtain an API key, see https://home.openweathermap.org/api_keys`)
+	}
+
+	if *cliLocation == "" {
+		return fmt.Errorf("Please specify a location using either the -l command-line flag, or by setting the WEATHERCASTER_LOCATION environment variable.")
+	}
+
+	options := []clientOption{WithSpeedUnit(speedUnit), WithTempUnit(tempUnit)}
+	options = append(options, cliAPIHostOptions()...)
+	if *cliVerbose {
+		options = append(options, WithSlogLogger(slog.New(slog.NewTextHandler(errOutput, nil))))
+	}
+
+	wc, err := NewClient(apiKey, options...)
 	if err != nil {
 		return fmt.Errorf("Error creating weather client: %v\n", err)
 	}
+	defer wc.Close()
 
-	forecast, err := wc.Forecast(*cliLocation)
-	if err != nil {
+	// Cancel an in-flight weather API request on SIGINT or SIGTERM, instead
+	// of waiting for it to time out.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if updateCheck, _ := strconv.ParseBool(os.Getenv("WEATHERCASTER_UPDATE_CHECK")); updateCheck {
+		// Check for a new version while getting the forecast. The result is
+		// only reported if the check finished by then, so it never delays
+		// exiting, and stop cancels a check which is still in flight.
+		newVersion := make(chan string, 1)
+		go func() {
+			latest, _, err := CheckForUpdates(ctx, Version())
+			if err == nil && latest != "" {
+				newVersion <- latest
+			}
+		}()
+		defer func() {
+			select {
+			case latest := <-newVersion:
+				fmt.Fprintf(errOutput, "A new version %s is available\n", latest)
+			default:
+			}
+		}()
+	}
+
+	forecastFunc := func(location string) (Conditions, string, error) {
+		return wc.forecastWithConditions(ctx, location)
+	}
+	if *cliJSON || *cliJSONPretty {
+		forecastFunc = func(location string) (Conditions, string, error) {
+			w, data, err := wc.forecastJSON(ctx, location, *cliJSONPretty)
+			return w, string(data), err
+		}
+	}
+	if *cliYAML {
+		forecastFunc = func(location string) (Conditions, string, error) {
+			w, data, err := wc.forecastYAML(ctx, location)
+			// The forecast is written with a trailing newline.
+			return w, strings.TrimSuffix(string(data), "\n"), err
+		}
+	}
+	if *cliVerbose {
+		// Report the total time, including processing the API response, to
+		// help diagnose whether slowness is from the network.
+		fetchForecast := forecastFunc
+		forecastFunc = func(location string) (Conditions, string, error) {
+			start := time.Now()
+			w, forecast, err := fetchForecast(location)
+			fmt.Fprintf(errOutput, "forecast fetched in %v\n", time.Since(start).Round(time.Millisecond))
+			return w, forecast, err
+		}
+	}
+
+	var w Conditions
+	var forecast string
+	if *cliLocation == "-" {
+		err = forecastLocations(forecastFunc, input, output, errOutput)
+	} else {
+		w, forecast, err = forecastFunc(*cliLocation)
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(errOutput, "interrupted")
+		return fmt.Errorf("Interrupted while getting a forecast: %w", err)
+	}
+	if err != nil || *cliLocation == "-" {
 		return err
 	}
 
+	writeForecast(w, forecast, output, errOutput)
+	return nil
+}
+
+// cliAPIHostOptions returns an option setting the API host to the
+// WEATHERCASTER_API_HOST environment variable, if it is set. The -test-server
+// CLI flag sets this variable to point the CLI at a fake weather API.
+func cliAPIHostOptions() []clientOption {
+	apiHost := os.Getenv("WEATHERCASTER_API_HOST")
+	if apiHost == "" {
+		return nil
+	}
+	return []clientOption{WithAPIHost(apiHost)}
+}
+
+// writeForecast writes a forecast to output. A cold or heat warning for the
+// forecast conditions is written on its own line to errOutput first, so the
+// forecast remains one line of output, and the warning is shown for every
+// output format.
+func writeForecast(w Conditions, forecast string, output, errOutput io.Writer) {
+	switch {
+	case w.ColdWarning():
+		fmt.Fprintln(errOutput, "⚠️ COLD WARNING")
+	case w.HeatWarning():
+		fmt.Fprintln(errOutput, "⚠️ HEAT WARNING")
+	}
 	fmt.Fprintln(output, forecast)
+}
+
+// forecastLocations reads newline-delimited locations from input, and writes
+// one forecast per location using writeForecast, as returned by forecastFunc.
+// An error for one location is written to errOutput without stopping forecasts
+// for the remaining locations.
+func forecastLocations(forecastFunc func(string) (Conditions, string, error), input io.Reader, output, errOutput io.Writer) error {
+	var total, failed int
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		location := strings.TrimSpace(scanner.Text())
+		if location == "" {
+			continue
+		}
+		total++
+
+		w, forecast, err := forecastFunc(location)
+		if errors.Is(err, context.Canceled) {
+			// Remaining locations would also be cancelled.
+			return err
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintln(errOutput, err)
+			continue
+		}
+		writeForecast(w, forecast, output, errOutput)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Error reading locations: %v", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("Unable to get a forecast for %d of %d locations", failed, total)
+	}
+	return nil
+}
+
+// convertKelvin writes one temperature to output for each Kelvin temperature
+// in values, converted to tempUnit. If values is empty, newline-delimited
+// temperatures are read from input.
+func convertKelvin(values []string, tempUnit TempUnit, input io.Reader, output io.Writer) error {
+	if len(values) == 0 {
+		scanner := bufio.NewScanner(input)
+		for scanner.Scan() {
+			if v := strings.TrimSpace(scanner.Text()); v != "" {
+				values = append(values, v)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("Error reading temperatures: %v", err)
+		}
+	}
+
+	for _, v := range values {
+		kelvin, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("Kelvin temperature %q is invalid, please specify a number.", v)
+		}
+		fmt.Fprintf(output, "%.1f%v\n", tempFromKelvin(kelvin, tempUnit), tempUnitName[tempUnit])
+	}
 	return nil
 }
 
+// ProcessCLIMeasurement converts a measurement system, as accepted by the
+// WEATHERCASTER_MEASUREMENT environment variable, into TempUnit* and
+// SpeedUnit* constants. The system is metric (Celsius and meters/sec) or
+// imperial (Fahrenheit and miles/hour), optionally followed by comma-separated
+// overrides for temperature or wind, such as "metric,wind=imperial." An
+// override is a system, or a unit as accepted by ProcessCLITempUnit or
+// ProcessCLISpeedUnit, such as "imperial,temp=k."
+func ProcessCLIMeasurement(s string) (TempUnit, SpeedUnit, error) {
+	systems := map[string]struct {
+		temp  TempUnit
+		speed SpeedUnit
+	}{
+		"metric":   {TempUnitCelsius, SpeedUnitMeters},
+		"imperial": {TempUnitFahrenheit, SpeedUnitMiles},
+	}
+
+	parts := strings.Split(strings.ToLower(s), ",")
+	system, ok := systems[strings.TrimSpace(parts[0])]
+	if !ok {
+		return 0, 0, fmt.Errorf("Measurement system %q is invalid, please specify metric or imperial, optionally followed by overrides such as \"metric,wind=imperial\".", parts[0])
+	}
+	tempUnit, speedUnit := system.temp, system.speed
+
+	for _, override := range parts[1:] {
+		field, value, found := strings.Cut(strings.TrimSpace(override), "=")
+		if !found || value == "" {
+			return 0, 0, fmt.Errorf("Measurement override %q is invalid, please specify temp=UNIT or wind=UNIT.", override)
+		}
+		overrideSystem, isSystem := systems[value]
+
+		var err error
+		switch field {
+		case "temp":
+			tempUnit = overrideSystem.temp
+			if !isSystem {
+				tempUnit, err = ProcessCLITempUnit(value)
+			}
+		case "wind":
+			speedUnit = overrideSystem.speed
+			if !isSystem {
+				speedUnit, err = ProcessCLISpeedUnit(value)
+			}
+		default:
+			return 0, 0, fmt.Errorf("Measurement override %q is invalid, please specify temp=UNIT or wind=UNIT.", override)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return tempUnit, speedUnit, nil
+}
+
 // ProcessCLISpeedUnit converts a string into a SpeedUnit* constant.
 func ProcessCLISpeedUnit(s string) (SpeedUnit, error) {
 	var u SpeedUnit
@@ -0,0 +1,188 @@
+package weather
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormAPIUrl(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewClient("0123456789abcdef0123456789abcdef", WithAPIHost("https://weather.example.com"))
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		uri         string
+		param       string
+		value       string
+		want        string
+	}{
+		{
+			description: "forecast includes cnt",
+			uri:         forecastURI,
+			param:       "q",
+			value:       "Great Neck Plaza,NY,US",
+			want:        "https://weather.example.com/data/2.5/forecast/?q=Great+Neck+Plaza%2CNY%2CUS&appid=0123456789abcdef0123456789abcdef&cnt=3",
+		},
+		{
+			description: "current weather omits cnt",
+			uri:         currentWeatherURI,
+			param:       "q",
+			value:       "London",
+			want:        "https://weather.example.com/data/2.5/weather/?q=London&appid=0123456789abcdef0123456789abcdef",
+		},
+		{
+			description: "geocoding omits cnt",
+			uri:         geocodingURI,
+			param:       "q",
+			value:       "London",
+			want:        "https://weather.example.com/geo/1.0/direct/?q=London&appid=0123456789abcdef0123456789abcdef",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			got := c.formEndpointURL(tc.uri, tc.param, tc.value, 3)
+			if tc.want != got {
+				t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+			}
+		})
+	}
+
+	t.Run("configured forecast endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		const want = "https://weather.example.com/data/2.5/forecast/?id=5119226&appid=0123456789abcdef0123456789abcdef&cnt=1"
+		got, err := c.formAPIUrl("id", "5119226", 1)
+		if err != nil {
+			t.Fatalf("Error forming API URL: %v", err)
+		}
+		if want != got {
+			t.Errorf("Want %q, got %q", want, got)
+		}
+	})
+
+	t.Run("renamed parameters", func(t *testing.T) {
+		t.Parallel()
+
+		rc, err := NewClient("0123456789abcdef0123456789abcdef",
+			WithAPIHost("https://weather.example.com"),
+			WithParamNames(map[string]string{"appid": "apikey"}),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		const want = "https://weather.example.com/data/2.5/forecast/?q=London&apikey=0123456789abcdef0123456789abcdef&cnt=1"
+		got, err := rc.formAPIUrl("q", "London", 1)
+		if err != nil {
+			t.Fatalf("Error forming API URL: %v", err)
+		}
+		if want != got {
+			t.Errorf("Want %q, got %q", want, got)
+		}
+
+		_, err = NewClient("0123456789abcdef0123456789abcdef", WithParamNames(map[string]string{"units": "u"}))
+		if err == nil {
+			t.Error("Want an error renaming a parameter the client does not use, got nil")
+		}
+	})
+
+	t.Run("invalid API URI", func(t *testing.T) {
+		t.Parallel()
+
+		for _, uri := range []string{"data/2.5/forecast", "/data/%zz/forecast"} {
+			ic := *c
+			ic.APIURI = uri
+			_, err := ic.formAPIUrl("q", "London", 1)
+			if err == nil {
+				t.Errorf("Want an error for API URI %q, got nil", uri)
+			}
+		}
+	})
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	const apiURL = "https://weather.example.com/data/2.5/forecast/?q=London&appid=0123456789abcdef0123456789abcdef&cnt=1"
+	key := Client{}.cacheKey(apiURL)
+	if len(key) != 64 {
+		t.Errorf("Want a 64 character SHA256 hex hash, got %q", key)
+	}
+	if strings.Contains(key, "0123456789abcdef0123456789abcdef") {
+		t.Errorf("Want a cache key without the API key, got %q", key)
+	}
+
+	testCases := []struct {
+		description string
+		apiURL      string
+		wantSame    bool
+	}{
+		{
+			description: "parameters in another order",
+			apiURL:      "https://weather.example.com/data/2.5/forecast/?cnt=1&appid=0123456789abcdef0123456789abcdef&q=London",
+			wantSame:    true,
+		},
+		{
+			description: "another API key",
+			apiURL:      "https://weather.example.com/data/2.5/forecast/?q=London&appid=fedcba9876543210fedcba9876543210&cnt=1",
+			wantSame:    true,
+		},
+		{
+			description: "another location",
+			apiURL:      "https://weather.example.com/data/2.5/forecast/?q=Paris&appid=0123456789abcdef0123456789abcdef&cnt=1",
+			wantSame:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		gotSame := Client{}.cacheKey(tc.apiURL) == key
+		if tc.wantSame != gotSame {
+			t.Errorf("Want same cache key %v, got %v, testing %v", tc.wantSame, gotSame, tc.description)
+		}
+	}
+}
+
+func TestFormatForecastNoWeatherData(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		strict      bool
+		wantErr     error
+	}{
+		{
+			description: "lenient",
+			strict:      false,
+			wantErr:     nil,
+		},
+		{
+			description: "strict",
+			strict:      true,
+			wantErr:     ErrNoWeatherData,
+		},
+	}
+
+	for _, tc := range testCases {
+		c, err := NewClient("0123456789abcdef0123456789abcdef", WithStrictFormatting(tc.strict), WithShortDescription(true))
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got, err := c.formatForecast(Conditions{})
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("Want error %v, got %v, testing %v", tc.wantErr, err, tc.description)
+		}
+		if noWeatherData != got {
+			t.Errorf("Want %q, got %q, testing %v", noWeatherData, got, tc.description)
+		}
+	}
+}
@@ -1,20 +1,47 @@
 package weather_test
 
 import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"log/slog"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 	"weather"
+
+	"gopkg.in/yaml.v3"
 )
 
+// testGreatNeckJSON is a forecast for Great Neck Plaza, NY, as though
+// served by the weather API.
+//
+//go:embed testdata/greatneck.json
+var testGreatNeckJSON []byte
+
+// testAPIKey is formatted as an OpenWeatherMap API key.
+const testAPIKey = "0123456789abcdef0123456789abcdef"
+
 func TestForecast(t *testing.T) {
 	t.Parallel()
 
 	const testLocation = "Great Neck Plaza,NY,US"
-	const testFileName = "testdata/greatneck.json"
-	const wantRequestURL = "/data/2.5/forecast/?q=Great+Neck+Plaza%2CNY%2CUS&appid=DummyAPIKey&cnt=1"
+	const wantRequestURL = "/data/2.5/forecast/?q=Great+Neck+Plaza%2CNY%2CUS&appid=0123456789abcdef0123456789abcdef&cnt=1"
 
 	// Define test cases
 	testCases := []struct {
@@ -51,20 +78,14 @@ func TestForecast(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		f, err := os.Open(testFileName)
-		if err != nil {
-			t.Fatalf("%v", err)
-		}
-		defer f.Close()
-
 		// Create a test HTTP server,
 		// and populate it with JSON as though served by the weather API.
 		// The `HandlerFunc` will be called when the test HTTP client
 		// queries the test server.
 		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			_, err := io.Copy(w, f)
+			_, err := io.Copy(w, bytes.NewReader(testGreatNeckJSON))
 			if err != nil {
-				t.Fatalf("unable to copy test JSON from file %s to test HTTP server: %v", testFileName, err)
+				t.Fatalf("unable to copy test JSON to test HTTP server: %v", err)
 			}
 			gotRequestURL := r.URL.String()
 			if wantRequestURL != gotRequestURL {
@@ -75,7 +96,7 @@ func TestForecast(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		wc, err := weather.NewClient("DummyAPIKey",
+		wc, err := weather.NewClient(testAPIKey,
 			weather.WithSpeedUnit(tc.setSpeedUnit),
 			weather.WithTempUnit(tc.setTempUnit),
 			weather.WithHTTPClient(ts.Client()),
@@ -99,84 +120,2264 @@ func TestForecast(t *testing.T) {
 		}
 	}
 }
-func TestProcessCLISpeedUnit(t *testing.T) {
+
+func TestForecastCustomURI(t *testing.T) {
+	t.Parallel()
+
+	const customURI = "/custom/v9/forecast"
+	const wantRequestURL = customURI + "/?q=Great+Neck+Plaza%2CNY%2CUS&appid=0123456789abcdef0123456789abcdef&cnt=1"
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wantRequestURL != r.URL.String() {
+			t.Errorf("Want request URL %q, got %q", wantRequestURL, r.URL.String())
+			http.NotFound(w, r)
+			return
+		}
+		_, err := w.Write(testGreatNeckJSON)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+		weather.WithAPIURI(customURI),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.Forecast("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting forecast: %v", err)
+	}
+
+	const want = "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph"
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+}
+
+// newTestServer returns a test HTTP server which serves the content of
+// fileName as though it were the weather API.
+func newTestServer(t *testing.T, fileName string) *httptest.Server {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return newTestServerWithBody(t, data)
+}
+
+// newTestServerWithBody returns a test HTTP server which serves body as
+// though it were the weather API.
+func newTestServerWithBody(t *testing.T, body []byte) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write(body)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestClient_RaceCondition is most useful when run with `go test -race`.
+func TestClient_RaceCondition(t *testing.T) {
+	t.Parallel()
+
+	const testLocation = "Great Neck Plaza,NY,US"
+	const want = "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph"
+	const numGoroutines = 10
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := wc.Forecast(testLocation)
+			if err != nil {
+				t.Errorf("Error while getting forecast for location %q: %v", testLocation, err)
+				return
+			}
+			if want != got {
+				t.Errorf("Want %q, got %q", want, got)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	recordCalls := func(name string) weather.RequestMiddleware {
+		return func(next weather.RoundTripFunc) weather.RoundTripFunc {
+			return func(r *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				resp, err := next(r)
+				calls = append(calls, name+" after")
+				return resp, err
+			}
+		}
+	}
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithMiddleware(recordCalls("first"), recordCalls("second")),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	_, err = wc.Forecast("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting forecast: %v", err)
+	}
+
+	want := []string{"first before", "second before", "second after", "first after"}
+	if !reflect.DeepEqual(want, calls) {
+		t.Errorf("Want %q, got %q comparing middleware order", want, calls)
+	}
+}
+
+func TestForecastLocationAliases(t *testing.T) {
 	t.Parallel()
 
-	// Define test cases
 	testCases := []struct {
-		userInput   string
-		want        weather.SpeedUnit
-		errExpected bool
+		location  string
+		wantQuery string
+		want      string
 	}{
 		{
-			userInput: "", // default case
-			want:      weather.SpeedUnitMiles,
+			location:  "home",
+			wantQuery: "Great Neck Plaza,NY,US",
+			want:      "home: overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
 		},
 		{
-			userInput: "meters",
-			want:      weather.SpeedUnitMeters,
+			location:  "mom's place",
+			wantQuery: "Miami,FL,US",
+			want:      "mom's place: overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
 		},
 		{
-			userInput: "miles",
-			want:      weather.SpeedUnitMiles,
+			location:  "gnp",
+			wantQuery: "Great Neck Plaza,NY,US",
+			want:      "gnp: overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
 		},
 		{
-			userInput:   "feet",
-			errExpected: true,
+			location:  "London",
+			wantQuery: "London",
+			want:      "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
+		},
+		{
+			location:  " New  York ,us",
+			wantQuery: "New York,US",
+			want:      "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
 		},
 	}
 
+	data := testGreatNeckJSON
+
 	for _, tc := range testCases {
-		got, err := weather.ProcessCLISpeedUnit(tc.userInput)
-		if !tc.errExpected && err != nil {
-			t.Fatalf("error for user input %q: %v", tc.userInput, err)
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotQuery := r.URL.Query().Get("q")
+			if tc.wantQuery != gotQuery {
+				t.Errorf("Want query %q, got %q, for location %q", tc.wantQuery, gotQuery, tc.location)
+			}
+			_, err := w.Write(data)
+			if err != nil {
+				t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+			}
+		}))
+		t.Cleanup(ts.Close)
+
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithLocationAliases(map[string]string{
+				"home":        "Great Neck Plaza,NY,US",
+				"mom's place": "Miami,FL,US",
+			}),
+			weather.WithLocationAlias("gnp", "Great Neck Plaza,NY,US"),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
 		}
 
-		if !tc.errExpected && tc.want != got {
-			t.Fatalf("want %q, got %q, for user input %q", tc.want, got, tc.userInput)
+		got, err := wc.Forecast(tc.location)
+		if err != nil {
+			t.Fatalf("Error while getting forecast for location %q: %v", tc.location, err)
 		}
+		if tc.want != got {
+			t.Errorf("Want %q, got %q", tc.want, got)
+		}
+	}
+
+	_, err := weather.NewClient(testAPIKey, weather.WithLocationAlias("gnp", ""))
+	if err == nil {
+		t.Error("Want an error for an alias of an empty location, got nil")
 	}
 }
 
-func TestProcessCLITempUnit(t *testing.T) {
+func TestNormalizeLocation(t *testing.T) {
 	t.Parallel()
 
-	// Define test cases
 	testCases := []struct {
-		userInput   string
-		want        weather.TempUnit
-		errExpected bool
+		location, want string
+	}{
+		{location: "New York ,us", want: "New York,US"},
+		{location: "  New   York , us ", want: "New York,US"},
+		{location: "new york", want: "new york"},
+		{location: "great neck plaza,ny,us", want: "great neck plaza,NY,US"},
+		{location: "Great Neck Plaza,NY,US", want: "Great Neck Plaza,NY,US"},
+		{location: "McAllen, tx, us", want: "McAllen,TX,US"},
+		{location: "DeKalb,il", want: "DeKalb,IL"},
+		{location: "Le Mans,fr", want: "Le Mans,FR"},
+		{location: "le  mans", want: "le mans"},
+		{location: "Paris,Île-de-France,fr", want: "Paris,Île-de-France,FR"},
+		{location: "london,gbr", want: "london,gbr"},
+		{location: "Ōu,jp", want: "Ōu,JP"},
+		{location: "", want: ""},
+	}
+
+	for _, tc := range testCases {
+		got := weather.NormalizeLocation(tc.location)
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %q", tc.want, got, tc.location)
+		}
+	}
+}
+
+func TestHourlyForecast(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(t, "testdata/greatneck_8slots.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.HourlyForecast("Great Neck Plaza,NY,US", 8)
+	if err != nil {
+		t.Fatalf("Error while getting hourly forecast: %v", err)
+	}
+	if len(got) != 8 {
+		t.Fatalf("Want 8 forecast entries, got %d", len(got))
+	}
+
+	// The first entry of both test files is the same.
+	want := greatNeckConditions(t)
+	if !want.Equal(got[0]) {
+		t.Errorf("Want %+v, got %+v, comparing the first entry", want, got[0])
+	}
+
+	for i := 1; i < len(got); i++ {
+		interval := got[i].Time.Sub(got[i-1].Time)
+		if interval < 170*time.Minute || interval > 190*time.Minute {
+			t.Errorf("Want entry %d about 3 hours after the previous, got %v", i, interval)
+		}
+	}
+}
+
+func TestTomorrowForecast(t *testing.T) {
+	t.Parallel()
+
+	// The test data begins at 11pm on April 10th, and ends at 8pm on April
+	// 11th, in a time zone 4 hours behind UTC.
+	ts := newTestServer(t, "testdata/greatneck_8slots.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+		weather.WithTempUnit(weather.TempUnitCelsius),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.TomorrowForecast("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting tomorrow's forecast: %v", err)
+	}
+
+	wantTime := time.Date(2021, time.April, 11, 11, 0, 0, 0, time.FixedZone("", -4*60*60))
+	if !wantTime.Equal(got.Time) {
+		t.Errorf("Want the entry at %v, got %v", wantTime, got.Time)
+	}
+	if got.Description == nil || *got.Description != "broken clouds" {
+		t.Errorf("Want description %q, got %v", "broken clouds", got.Description)
+	}
+	if got.Temperature == nil || roundTenth(*got.Temperature) != 14.5 {
+		t.Errorf("Want temperature 14.5, got %v", got.Temperature)
+	}
+
+	// A single entry does not reach tomorrow.
+	ts = newTestServer(t, "testdata/greatneck.json")
+	wc, err = weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+	_, err = wc.TomorrowForecast("Great Neck Plaza,NY,US")
+	if err == nil || !strings.Contains(err.Error(), "does not reach tomorrow") {
+		t.Errorf("Want an error that the forecast does not reach tomorrow, got %v", err)
+	}
+}
+
+func TestTemperatureSparkline(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(t, "testdata/greatneck_8slots.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	// The lowest temperature is the third entry, and the highest is the
+	// sixth.
+	const want = "▃▂▁▂▅█▇▄"
+	got, err := wc.TemperatureSparkline("Great Neck Plaza,NY,US", 8)
+	if err != nil {
+		t.Fatalf("Error while getting temperature sparkline: %v", err)
+	}
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+
+	// The test server returns 8 entries regardless of how many are requested.
+	got, err = wc.TemperatureSparkline("Great Neck Plaza,NY,US", 3)
+	if err != nil {
+		t.Fatalf("Error while getting temperature sparkline: %v", err)
+	}
+	if n := len([]rune(got)); n != 3 {
+		t.Errorf("Want a sparkline of length 3, got %d: %q", n, got)
+	}
+}
+
+func TestForecastByCityID(t *testing.T) {
+	t.Parallel()
+
+	const testCityID = 5119226
+	const wantRequestURL = "/data/2.5/forecast/?id=5119226&appid=0123456789abcdef0123456789abcdef&cnt=1"
+	const want = "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph"
+
+	data := testGreatNeckJSON
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestURL := r.URL.String()
+		if wantRequestURL != gotRequestURL {
+			t.Errorf("Want %q, got %q comparing API URI", wantRequestURL, gotRequestURL)
+		}
+		_, err := w.Write(data)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.ForecastByCityID(testCityID)
+	if err != nil {
+		t.Fatalf("Error while getting forecast for city ID %d: %v", testCityID, err)
+	}
+
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+}
+
+func TestForecastHTTPErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		status int
+		want   string
+	}{
+		{status: http.StatusBadRequest, want: "HTTP 400 Bad Request returned from weather API"},
+		{status: http.StatusNotFound, want: "HTTP 404 Not Found returned from weather API"},
+		{status: http.StatusInternalServerError, want: "HTTP 500 Internal Server Error returned from weather API"},
+	}
+
+	for _, tc := range testCases {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+		}))
+		t.Cleanup(ts.Close)
+
+		wc, err := weather.NewClient(testAPIKey, weather.WithAPIHost(ts.URL))
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		_, err = wc.Forecast("London")
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Want an error containing %q, got %v", tc.want, err)
+		}
+	}
+
+	// A closed server returns a network error.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+	wc, err := weather.NewClient(testAPIKey, weather.WithAPIHost(ts.URL))
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+	_, err = wc.Forecast("London")
+	if err == nil {
+		t.Error("Want an error from a closed server, got nil")
+	}
+}
+
+func TestForecastSelectionStrategy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		strategy string
+		want     string
 	}{
 		{
-			userInput: "", // default case
-			want:      weather.TempUnitFahrenheit,
+			strategy: weather.SelectionFirst,
+			want:     "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
 		},
 		{
-			userInput: "f",
-			want:      weather.TempUnitFahrenheit,
+			// All entries in the test data are in the past, so the latest is
+			// nearest to now.
+			strategy: weather.SelectionNearestNow,
+			want:     "broken clouds, temp 61.2 ºF, feels like 60.3 ºF, humidity 80.0%, wind 9.4 mph",
 		},
 		{
-			userInput: "c",
-			want:      weather.TempUnitCelsius,
+			strategy: weather.SelectionAggregateMinMax,
+			want:     "overcast clouds, temp 52.7 to 61.2 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
 		},
+	}
+
+	ts := newTestServer(t, "testdata/greatneck_multi.json")
+
+	for _, tc := range testCases {
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithSelectionStrategy(tc.strategy),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for strategy %q: %v", tc.strategy, err)
+		}
+
+		got, err := wc.Forecast("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting forecast for strategy %q: %v", tc.strategy, err)
+		}
+
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing strategy %q", tc.want, got, tc.strategy)
+		}
+	}
+
+	_, err := weather.NewClient(testAPIKey, weather.WithSelectionStrategy("last"))
+	if err == nil {
+		t.Errorf("Want an error for an invalid selection strategy, got nil")
+	}
+}
+
+func TestForecastPrimaryConditionStrategy(t *testing.T) {
+	t.Parallel()
+
+	// The test data has mist, overcast clouds, then light rain.
+	testCases := []struct {
+		strategy string
+		want     string
+	}{
 		{
-			userInput: "k",
-			want:      weather.TempUnitKelvin,
+			strategy: weather.PrimaryConditionFirst,
+			want:     "mist, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
 		},
 		{
-			userInput:   "x",
-			errExpected: true,
+			strategy: weather.PrimaryConditionSeverity,
+			want:     "light rain, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
 		},
 	}
 
+	ts := newTestServer(t, "testdata/greatneck_multicondition.json")
+
 	for _, tc := range testCases {
-		got, err := weather.ProcessCLITempUnit(tc.userInput)
-		if !tc.errExpected && err != nil {
-			t.Fatalf("error for user input %q: %v", tc.userInput, err)
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithPrimaryConditionStrategy(tc.strategy),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for strategy %q: %v", tc.strategy, err)
 		}
 
-		if !tc.errExpected && tc.want != got {
-			t.Fatalf("want %q, got %q, for user input %q", tc.want, got, tc.userInput)
+		got, err := wc.Forecast("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting forecast for strategy %q: %v", tc.strategy, err)
+		}
+
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing strategy %q", tc.want, got, tc.strategy)
+		}
+	}
+
+	_, err := weather.NewClient(testAPIKey, weather.WithPrimaryConditionStrategy("last"))
+	if err == nil {
+		t.Errorf("Want an error for an invalid primary condition strategy, got nil")
+	}
+}
+
+func TestForecastFieldSeparator(t *testing.T) {
+	t.Parallel()
+
+	const testLocation = "Great Neck Plaza,NY,US"
+	const want = "overcast clouds | temp 55.4 ºF | feels like 54.9 ºF | humidity 92.0% | wind 5.6 mph"
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithFieldSeparator(" | "),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.Forecast(testLocation)
+	if err != nil {
+		t.Fatalf("Error while getting forecast for location %q: %v", testLocation, err)
+	}
+
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+}
+
+func TestForecastMaxDescriptionLength(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description          string
+		weatherDescription   string
+		maxDescriptionLength int
+		want                 string
+	}{
+		{
+			description:          "long description truncated",
+			weatherDescription:   "thunderstorm with heavy rain",
+			maxDescriptionLength: 13,
+			want:                 "thunderstorm…, temp 55.4 ºF",
+		},
+		{
+			description:          "multi-byte description truncated",
+			weatherDescription:   "ciel dégagé",
+			maxDescriptionLength: 8,
+			want:                 "ciel dé…, temp 55.4 ºF",
+		},
+		{
+			description:          "short description untouched",
+			weatherDescription:   "overcast clouds",
+			maxDescriptionLength: 20,
+			want:                 "overcast clouds, temp 55.4 ºF",
+		},
+		{
+			description:          "description exactly at the limit untouched",
+			weatherDescription:   "overcast clouds",
+			maxDescriptionLength: 15,
+			want:                 "overcast clouds, temp 55.4 ºF",
+		},
+	}
+
+	for _, tc := range testCases {
+		body := fmt.Sprintf(`{"list":[{"weather":[{"description":%q}],"main":{"temp":286}}]}`, tc.weatherDescription)
+		ts := newTestServerWithBody(t, []byte(body))
+
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithMaxDescriptionLength(tc.maxDescriptionLength),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got, err := wc.Forecast("London")
+		if err != nil {
+			t.Fatalf("Error while getting forecast for test %v: %v", tc.description, err)
+		}
+
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestForecastCalmWindLabel(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description   string
+		calmWindLabel bool
+		want          string
+	}{
+		{
+			description:   "calm wind label",
+			calmWindLabel: true,
+			want:          "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind calm",
+		},
+		{
+			description:   "numeric wind",
+			calmWindLabel: false,
+			want:          "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 0.0 mph",
+		},
+	}
+
+	ts := newTestServer(t, "testdata/calm.json")
+
+	for _, tc := range testCases {
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithCalmWindLabel(tc.calmWindLabel),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got, err := wc.Forecast("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting forecast for test %v: %v", tc.description, err)
+		}
+
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestForecastDisallowUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	// This body only includes fields used by the weather client, plus
+	// `snow_depth`.
+	const body = `{
+  "cod": "200",
+  "list": [
+    {
+      "dt": 1637182800,
+      "main": {"temp": 286.14, "feels_like": 285.87, "humidity": 92},
+      "weather": [{"main": "Clouds", "description": "overcast clouds"}],
+      "wind": {"speed": 2.49, "deg": 180},
+      "pop": 0,
+      "snow_depth": 0
+    }
+  ],
+  "city": {"coord": {"lat": 40.7876, "lon": -73.7262}, "timezone": -18000}
+}`
+
+	testCases := []struct {
+		description string
+		disallow    bool
+		errExpected bool
+	}{
+		{
+			description: "lenient",
+			disallow:    false,
+			errExpected: false,
+		},
+		{
+			description: "strict",
+			disallow:    true,
+			errExpected: true,
+		},
+	}
+
+	ts := newTestServerWithBody(t, []byte(body))
+
+	for _, tc := range testCases {
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithDisallowUnknownFields(tc.disallow),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		_, err = wc.ForecastConditions("Great Neck Plaza,NY,US")
+		errReceived := err != nil
+		if tc.errExpected != errReceived {
+			t.Errorf("Want error %v, got %v, testing %v", tc.errExpected, err, tc.description)
+		}
+		if errReceived && !strings.Contains(err.Error(), "snow_depth") {
+			t.Errorf("Want an error naming the unknown field, got %v, testing %v", err, tc.description)
+		}
+	}
+}
+
+func TestForecastImplausibleHumidity(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description   string
+		humidity      string
+		clampHumidity bool
+		want          string
+		wantErr       bool
+	}{
+		{
+			description:   "humidity above 100 clamped",
+			humidity:      "150",
+			clampHumidity: true,
+			want:          "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 100.0%, wind 5.6 mph",
+		},
+		{
+			description:   "humidity below 0 clamped",
+			humidity:      "-5",
+			clampHumidity: true,
+			want:          "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 0.0%, wind 5.6 mph",
+		},
+		{
+			description: "humidity above 100 rejected",
+			humidity:    "150",
+			wantErr:     true,
+		},
+		{
+			description: "humidity below 0 rejected",
+			humidity:    "-5",
+			wantErr:     true,
+		},
+	}
+
+	data := testGreatNeckJSON
+
+	for _, tc := range testCases {
+		body := bytes.Replace(data, []byte(`"humidity": 92`), []byte(`"humidity": `+tc.humidity), 1)
+		ts := newTestServerWithBody(t, body)
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithClampHumidity(tc.clampHumidity),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got, err := wc.Forecast("Great Neck Plaza,NY,US")
+		if tc.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "implausible humidity "+tc.humidity+"%") {
+				t.Errorf("Want an implausible humidity error, got %v, testing %v", err, tc.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Error while getting forecast for test %v: %v", tc.description, err)
+		}
+
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestForecastDual(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, err := w.Write(testGreatNeckJSON)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithTempUnit(weather.TempUnitKelvin),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	metric, imperial, err := wc.ForecastDual("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting dual forecast: %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Want 1 weather API request, got %d", got)
+	}
+
+	if metric.TempUnit != weather.TempUnitCelsius || metric.SpeedUnit != weather.SpeedUnitMeters {
+		t.Errorf("Want metric units Celsius and meters, got %v and %v", metric.TempUnit, metric.SpeedUnit)
+	}
+	if imperial.TempUnit != weather.TempUnitFahrenheit || imperial.SpeedUnit != weather.SpeedUnitMiles {
+		t.Errorf("Want imperial units Fahrenheit and miles, got %v and %v", imperial.TempUnit, imperial.SpeedUnit)
+	}
+
+	// Each should match the conditions from a client using those units.
+	testCases := []struct {
+		description string
+		tempUnit    weather.TempUnit
+		speedUnit   weather.SpeedUnit
+		got         weather.Conditions
+	}{
+		{
+			description: "metric",
+			tempUnit:    weather.TempUnitCelsius,
+			speedUnit:   weather.SpeedUnitMeters,
+			got:         metric,
+		},
+		{
+			description: "imperial",
+			tempUnit:    weather.TempUnitFahrenheit,
+			speedUnit:   weather.SpeedUnitMiles,
+			got:         imperial,
+		},
+	}
+
+	for _, tc := range testCases {
+		uc, err := weather.NewClient(testAPIKey,
+			weather.WithTempUnit(tc.tempUnit),
+			weather.WithSpeedUnit(tc.speedUnit),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		want, err := uc.ForecastConditions("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting forecast conditions for test %v: %v", tc.description, err)
+		}
+		if !want.Equal(tc.got) {
+			t.Errorf("Want %+v, got %+v, testing %v", want, tc.got, tc.description)
+		}
+	}
+
+	// The wind speeds should agree with each other.
+	gotRatio := *imperial.WindSpeed / *metric.WindSpeed
+	if math.Abs(gotRatio-2.236936) > 1e-6 {
+		t.Errorf("Want imperial wind speed 2.236936 times metric, got %v times", gotRatio)
+	}
+}
+
+func TestDaylightHours(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		body        []byte
+		want        time.Duration
+		errExpected bool
+	}{
+		{
+			description: "sunrise and sunset",
+			body:        testGreatNeckJSON,
+			want:        13*time.Hour + 5*time.Minute + 21*time.Second,
+		},
+		{
+			description: "sunset before sunrise",
+			body:        bytes.Replace(testGreatNeckJSON, []byte(`"sunset": 1618097315`), []byte(`"sunset": 1618046594`), 1),
+			want:        23 * time.Hour,
+		},
+		{
+			description: "polar night",
+			body:        bytes.Replace(testGreatNeckJSON, []byte(`"sunrise": 1618050194`), []byte(`"sunrise": 0`), 1),
+			errExpected: true,
+		},
+		{
+			description: "missing sunset",
+			body:        bytes.Replace(testGreatNeckJSON, []byte(`"sunset"`), []byte(`"moonset"`), 1),
+			errExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		ts := newTestServerWithBody(t, tc.body)
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got, err := wc.DaylightHours("Great Neck Plaza,NY,US")
+		errReceived := err != nil
+		if tc.errExpected != errReceived {
+			t.Fatalf("Want error %v, got %v, testing %v", tc.errExpected, err, tc.description)
+		}
+		if tc.want != got {
+			t.Errorf("Want %v, got %v, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestForecastDualTempDisplay(t *testing.T) {
+	t.Parallel()
+
+	const want = "overcast clouds, temp 12.9 ºC / 55.4 ºF, feels like 12.6 ºC / 54.9 ºF, humidity 92.0%, wind 5.6 mph"
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+
+	for _, u := range []weather.TempUnit{weather.TempUnitFahrenheit, weather.TempUnitCelsius, weather.TempUnitKelvin} {
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithDualTempDisplay(true),
+			weather.WithTempUnit(u),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for temperature unit %v: %v", u, err)
+		}
+
+		got, err := wc.Forecast("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting forecast for temperature unit %v: %v", u, err)
+		}
+		if want != got {
+			t.Errorf("Want %q, got %q, testing temperature unit %v", want, got, u)
+		}
+	}
+}
+
+func TestWebURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		body        []byte
+		want        string
+		errExpected bool
+	}{
+		{
+			description: "city ID",
+			body:        testGreatNeckJSON,
+			want:        "https://openweathermap.org/city/5119226",
+		},
+		{
+			description: "coordinates without a city ID",
+			body:        bytes.Replace(testGreatNeckJSON, []byte(`"id": 5119226`), []byte(`"id": 0`), 1),
+			want:        "https://openweathermap.org/weathermap?zoom=10&lat=40.7868&lon=-73.7265",
+		},
+		{
+			description: "no city ID or coordinates",
+			body:        []byte(`{"cod": "200", "list": [{"weather": [{"description": "clear sky"}]}]}`),
+			errExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		ts := newTestServerWithBody(t, tc.body)
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got, err := wc.WebURL("Great Neck Plaza,NY,US")
+		errReceived := err != nil
+		if tc.errExpected != errReceived {
+			t.Fatalf("Want error %v, got %v, testing %v", tc.errExpected, err, tc.description)
+		}
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestForecastShortDescription(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description      string
+		shortDescription bool
+		want             string
+	}{
+		{
+			description:      "short description",
+			shortDescription: true,
+			want:             "Clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
+		},
+		{
+			description:      "long description",
+			shortDescription: false,
+			want:             "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
+		},
+	}
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+
+	for _, tc := range testCases {
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithShortDescription(tc.shortDescription),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got, err := wc.Forecast("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting forecast for test %v: %v", tc.description, err)
+		}
+
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+
+	// Both descriptions are available in structured conditions.
+	w := greatNeckConditions(t)
+	if w.ShortDescription == nil || *w.ShortDescription != "Clouds" {
+		t.Errorf("Want short description %q, got %v", "Clouds", w.ShortDescription)
+	}
+	if w.Description == nil || *w.Description != "overcast clouds" {
+		t.Errorf("Want description %q, got %v", "overcast clouds", w.Description)
+	}
+}
+
+func TestForecastUnitsFooter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		speedUnit   weather.SpeedUnit
+		tempUnit    weather.TempUnit
+		unitsFooter bool
+		want        string
+	}{
+		{
+			description: "Fahrenheit and miles",
+			speedUnit:   weather.SpeedUnitMiles,
+			tempUnit:    weather.TempUnitFahrenheit,
+			unitsFooter: true,
+			want:        "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph\n[units: temp=ºF, wind=mph]",
+		},
+		{
+			description: "Kelvin and meters",
+			speedUnit:   weather.SpeedUnitMeters,
+			tempUnit:    weather.TempUnitKelvin,
+			unitsFooter: true,
+			want:        "overcast clouds, temp 286.0K, feels like 285.7K, humidity 92.0%, wind 2.5 m/s\n[units: temp=K, wind=m/s]",
+		},
+		{
+			description: "no footer",
+			speedUnit:   weather.SpeedUnitMiles,
+			tempUnit:    weather.TempUnitFahrenheit,
+			unitsFooter: false,
+			want:        "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
+		},
+	}
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+
+	for _, tc := range testCases {
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithSpeedUnit(tc.speedUnit),
+			weather.WithTempUnit(tc.tempUnit),
+			weather.WithUnitsFooter(tc.unitsFooter),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got, err := wc.Forecast("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting forecast for test %v: %v", tc.description, err)
+		}
+
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestFormatWind(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		speedUnit   weather.SpeedUnit
+		speed, deg  float64
+		want        string
+	}{
+		{description: "with direction", speedUnit: weather.SpeedUnitMiles, speed: 12.3, deg: 240, want: "240@12mph"},
+		{description: "direction padded", speedUnit: weather.SpeedUnitMeters, speed: 2.5, deg: 45.4, want: "045@2m/s"},
+		{description: "north", speedUnit: weather.SpeedUnitMiles, speed: 5.6, deg: 0, want: "000@6mph"},
+		{description: "without direction", speedUnit: weather.SpeedUnitMiles, speed: 12.3, deg: math.NaN(), want: "12mph"},
+		{description: "negative direction", speedUnit: weather.SpeedUnitMeters, speed: 2.5, deg: -1, want: "2m/s"},
+	}
+
+	for _, tc := range testCases {
+		wc, err := weather.NewClient(testAPIKey, weather.WithSpeedUnit(tc.speedUnit))
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got := wc.FormatWind(tc.speed, tc.deg)
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+}
+
+func TestForecastWindStyle(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description      string
+		windStyle        string
+		withoutDirection bool
+		want             string
+	}{
+		{
+			description: "compact",
+			windStyle:   weather.WindStyleCompact,
+			want:        "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 180@6mph",
+		},
+		{
+			description:      "compact without direction",
+			windStyle:        weather.WindStyleCompact,
+			withoutDirection: true,
+			want:             "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 6mph",
+		},
+		{
+			description: "default",
+			windStyle:   weather.WindStyleDefault,
+			want:        "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph",
+		},
+	}
+
+	data := testGreatNeckJSON
+
+	for _, tc := range testCases {
+		body := data
+		if tc.withoutDirection {
+			body = bytes.Replace(data, []byte(`"deg": 180`), []byte(`"gust": 3.2`), 1)
+		}
+		ts := newTestServerWithBody(t, body)
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithWindStyle(tc.windStyle),
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client for test %v: %v", tc.description, err)
+		}
+
+		got, err := wc.Forecast("Great Neck Plaza,NY,US")
+		if err != nil {
+			t.Fatalf("Error while getting forecast for test %v: %v", tc.description, err)
+		}
+
+		if tc.want != got {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, got, tc.description)
+		}
+	}
+
+	_, err := weather.NewClient(testAPIKey, weather.WithWindStyle("verbose"))
+	if err == nil {
+		t.Error("Want an error for an invalid wind style, got nil")
+	}
+}
+
+func TestForecastShowCoordinates(t *testing.T) {
+	t.Parallel()
+
+	const testLocation = "Great Neck Plaza,NY,US"
+	const want = "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph (40.79, -73.73)"
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithShowCoordinates(true),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	got, err := wc.Forecast(testLocation)
+	if err != nil {
+		t.Fatalf("Error while getting forecast for location %q: %v", testLocation, err)
+	}
+
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+}
+
+func TestQueryAPI_ResponseBodySizeLimit(t *testing.T) {
+	t.Parallel()
+
+	const testLocation = "Great Neck Plaza,NY,US"
+	// 100 bytes of valid JSON.
+	body := `{"list":[{"weather":[{"description":"overcast clouds"}],"main":{"temp":286}}],"padding":"` + strings.Repeat("x", 9) + `"}`
+	if len(body) != 100 {
+		t.Fatalf("test response body is %d bytes, want 100", len(body))
+	}
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, body)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithMaxResponseSize(10),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	_, err = wc.Forecast(testLocation)
+	if err == nil {
+		t.Fatalf("Want an error for a response larger than the limit, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "response body too large") {
+		t.Errorf("Want an error containing %q, got %q", "response body too large", err)
+	}
+
+	// The truncated JSON should not have been parsed.
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		t.Errorf("Want a size limit error, got a JSON syntax error: %v", err)
+	}
+}
+
+func TestValidateAPIKeyFormat(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		key         string
+		errExpected bool
+	}{
+		{key: testAPIKey},
+		{key: "0123456789ABCDEF0123456789ABCDEF"},
+		{key: "", errExpected: true},
+		{key: "DummyAPIKey", errExpected: true},
+		{key: "0123456789abcdef0123456789abcdeg", errExpected: true},
+		{key: "0123456789abcdef0123456789abcdef0", errExpected: true},
+	}
+
+	for _, tc := range testCases {
+		err := weather.ValidateAPIKeyFormat(tc.key)
+		if tc.errExpected && err == nil {
+			t.Errorf("Want an error for API key %q, got nil", tc.key)
+		}
+		if !tc.errExpected && err != nil {
+			t.Errorf("Want no error for API key %q, got %v", tc.key, err)
+		}
+	}
+
+	_, err := weather.NewClient("DummyAPIKey")
+	if err == nil {
+		t.Fatalf("Want an error creating a client with a malformed API key, got nil")
+	}
+	if !strings.Contains(err.Error(), "Client.Ping()") {
+		t.Errorf("Want an error suggesting Client.Ping(), got %q", err)
+	}
+}
+
+func TestWithSlogLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	ts := newFailingTestServer(t, "testdata/greatneck.json")
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithSlogLogger(logger),
+		weather.WithStaleIfError(time.Hour),
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	// The first query succeeds, the second returns stale conditions.
+	for i := 0; i < 2; i++ {
+		_, err = wc.ForecastConditions("London")
+		if err != nil {
+			t.Fatalf("Error while getting forecast conditions: %v", err)
+		}
+	}
+
+	if strings.Contains(buf.String(), testAPIKey) {
+		t.Errorf("Want the API key redacted from log records, got %s", buf.String())
+	}
+
+	type record struct {
+		Msg        string
+		URL        string
+		Duration   *int64
+		StatusCode int  `json:"status_code"`
+		CacheHit   bool `json:"cache_hit"`
+	}
+	var queries []record
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var r record
+		err := decoder.Decode(&r)
+		if err != nil {
+			t.Fatalf("Error decoding log record: %v", err)
+		}
+		if r.Msg == "weather API query" {
+			queries = append(queries, r)
+		}
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("Want 2 weather API query log records, got %d", len(queries))
+	}
+	const wantURL = "/data/2.5/forecast/?q=London&appid=REDACTED&cnt=1"
+	wantStatus := []int{http.StatusOK, http.StatusServiceUnavailable}
+	wantCacheHit := []bool{false, true}
+	for i, r := range queries {
+		if !strings.HasSuffix(r.URL, wantURL) {
+			t.Errorf("Want url ending in %q, got %q", wantURL, r.URL)
+		}
+		if r.Duration == nil {
+			t.Error("Want a duration, got none")
+		}
+		if wantStatus[i] != r.StatusCode {
+			t.Errorf("Want status_code %d, got %d", wantStatus[i], r.StatusCode)
+		}
+		if wantCacheHit[i] != r.CacheHit {
+			t.Errorf("Want cache_hit %v, got %v", wantCacheHit[i], r.CacheHit)
+		}
+	}
+}
+
+func TestAppendCSV(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = wc.AppendCSV(&buf, "Great Neck Plaza,NY,US", true)
+	if err != nil {
+		t.Fatalf("Error appending CSV: %v", err)
+	}
+	err = wc.AppendCSV(&buf, "Great Neck Plaza,NY,US", false)
+	if err != nil {
+		t.Fatalf("Error appending CSV: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Error reading appended CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Want a header and 2 rows, got %d rows: %q", len(rows), rows)
+	}
+
+	wantHeader := []string{"timestamp", "time", "description", "temperature", "feels_like", "humidity", "wind_speed", "wind_direction", "temp_unit", "speed_unit"}
+	if !reflect.DeepEqual(wantHeader, rows[0]) {
+		t.Errorf("Want header %q, got %q", wantHeader, rows[0])
+	}
+
+	wantRow := []string{"2021-04-10T23:00:00-04:00", "overcast clouds", "55.4", "54.9", "92.0", "5.6", "180.0", "fahrenheit", "miles"}
+	for _, row := range rows[1:] {
+		_, err := time.Parse(time.RFC3339, row[0])
+		if err != nil {
+			t.Errorf("Want an RFC3339 timestamp, got %q: %v", row[0], err)
+		}
+		if !reflect.DeepEqual(wantRow, row[1:]) {
+			t.Errorf("Want row %q, got %q", wantRow, row[1:])
+		}
+	}
+}
+
+func TestPing(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	err = wc.Ping()
+	if err != nil {
+		t.Errorf("Error pinging weather API: %v", err)
+	}
+}
+
+func TestPingMethod(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		allowHead   bool
+		wantMethods []string
+	}{
+		{
+			description: "HEAD allowed",
+			allowHead:   true,
+			wantMethods: []string{http.MethodHead},
+		},
+		{
+			description: "HEAD not allowed",
+			allowHead:   false,
+			wantMethods: []string{http.MethodHead, http.MethodGet},
+		},
+	}
+
+	for _, tc := range testCases {
+		var mu sync.Mutex
+		var gotMethods []string
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			gotMethods = append(gotMethods, r.Method)
+			mu.Unlock()
+			if r.Method == http.MethodHead && !tc.allowHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			_, err := w.Write(testGreatNeckJSON)
+			if err != nil {
+				t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+			}
+		}))
+		t.Cleanup(ts.Close)
+
+		wc, err := weather.NewClient(testAPIKey,
+			weather.WithHTTPClient(ts.Client()),
+			weather.WithAPIHost(ts.URL),
+		)
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client: %v", err)
+		}
+
+		err = wc.Ping()
+		if err != nil {
+			t.Errorf("Error pinging weather API, testing %v: %v", tc.description, err)
+		}
+		mu.Lock()
+		if !reflect.DeepEqual(tc.wantMethods, gotMethods) {
+			t.Errorf("Want methods %v, got %v, testing %v", tc.wantMethods, gotMethods, tc.description)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithAPIHost("https://weather.example.com"),
+		weather.WithSpeedUnit(weather.SpeedUnitMeters),
+		weather.WithTempUnit(weather.TempUnitCelsius),
+		weather.WithFieldSeparator(" | "),
+		weather.WithShowCoordinates(true),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	want := weather.ClientConfig{
+		APIKey:            "REDACTED",
+		APIHost:           "https://weather.example.com",
+		APIURI:            "/data/2.5/forecast",
+		SpeedUnit:         weather.SpeedUnitMeters,
+		TempUnit:          weather.TempUnitCelsius,
+		Timeout:           "3s",
+		FieldSeparator:    " | ",
+		ShowCoordinates:   true,
+		SelectionStrategy: weather.SelectionFirst,
+		PrimaryCondition:  weather.PrimaryConditionFirst,
+		WindStyle:         weather.WindStyleDefault,
+	}
+	got := wc.Config()
+	if want != got {
+		t.Errorf("Want %+v, got %+v", want, got)
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Error marshaling config to JSON: %v", err)
+	}
+	if strings.Contains(string(data), testAPIKey) {
+		t.Errorf("Want the API key redacted from JSON config, got %s", data)
+	}
+	if !strings.Contains(string(data), `"speed_unit":"meters","temp_unit":"celsius"`) {
+		t.Errorf("Want units represented by name in JSON config, got %s", data)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		newClient   func() (*weather.Client, error)
+		want        time.Duration
+	}{
+		{
+			description: "default timeout",
+			newClient:   func() (*weather.Client, error) { return weather.NewClient(testAPIKey) },
+			want:        3 * time.Second,
+		},
+		{
+			description: "no timeout",
+			newClient: func() (*weather.Client, error) {
+				return weather.NewClient(testAPIKey, weather.WithNoTimeout())
+			},
+			want: 0,
+		},
+		{
+			description: "no timeout after a timeout",
+			newClient: func() (*weather.Client, error) {
+				return weather.NewClient(testAPIKey, weather.WithTimeout(10*time.Second), weather.WithNoTimeout())
+			},
+			want: 0,
+		},
+		{
+			description: "timeout after no timeout",
+			newClient: func() (*weather.Client, error) {
+				return weather.NewClient(testAPIKey, weather.WithNoTimeout(), weather.WithTimeout(10*time.Second))
+			},
+			want: 10 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		wc, err := tc.newClient()
+		if err != nil {
+			t.Fatalf("Error while instanciating weather client, testing %v: %v", tc.description, err)
+		}
+		got := wc.HTTPClient.Timeout
+		if tc.want != got {
+			t.Errorf("Want %v, got %v, testing %v", tc.want, got, tc.description)
+		}
+	}
+
+	_, err := weather.NewClient(testAPIKey, weather.WithTimeout(0))
+	if err == nil {
+		t.Error("Want an error for a zero timeout, got nil")
+	}
+}
+
+func TestReportAccuracy(t *testing.T) {
+	t.Parallel()
+
+	forecastedAt := time.Date(2021, time.April, 11, 3, 0, 0, 0, time.UTC)
+	forecast := greatNeckConditions(t)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Want method %q, got %q", http.MethodPost, r.Method)
+		}
+		var got struct {
+			Location          string
+			ForecastedAt      time.Time `json:"forecasted_at"`
+			Forecast          map[string]interface{}
+			ActualDescription string `json:"actual_description"`
+			APIURI            string `json:"api_uri"`
+		}
+		d := json.NewDecoder(r.Body)
+		d.DisallowUnknownFields()
+		err := d.Decode(&got)
+		if err != nil {
+			t.Errorf("unable to decode feedback request body: %v", err)
+		}
+		if got.Location != "Great Neck Plaza,NY,US" || !got.ForecastedAt.Equal(forecastedAt) || got.ActualDescription != "light rain" || got.APIURI != "/data/2.5/forecast" {
+			t.Errorf("Want the location, forecast time, actual description, and API URI in the feedback body, got %+v", got)
+		}
+		wantForecast := map[string]interface{}{
+			"description": "overcast clouds",
+			"temperature": *forecast.Temperature,
+			"humidity":    92.0,
+			"temp_unit":   "fahrenheit",
+			"speed_unit":  "miles",
+		}
+		for k, want := range wantForecast {
+			if want != got.Forecast[k] {
+				t.Errorf("Want forecast field %q %v, got %v comparing feedback body", k, want, got.Forecast[k])
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	// Reporting is disabled until a feedback endpoint is configured.
+	wc, err := weather.NewClient(testAPIKey, weather.WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	err = wc.ReportAccuracy("Great Neck Plaza,NY,US", forecastedAt, forecast, "light rain")
+	if err == nil {
+		t.Errorf("Want an error reporting accuracy without a feedback endpoint, got nil")
+	}
+
+	var middlewareCalls int
+	countCalls := func(next weather.RoundTripFunc) weather.RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			middlewareCalls++
+			return next(r)
+		}
+	}
+	wc, err = weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithFeedbackURL(ts.URL),
+		weather.WithMiddleware(countCalls),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	err = wc.ReportAccuracy("Great Neck Plaza,NY,US", forecastedAt, forecast, "light rain")
+	if err != nil {
+		t.Errorf("Error reporting accuracy: %v", err)
+	}
+	if middlewareCalls != 1 {
+		t.Errorf("Want 1 feedback request through the middleware, got %d", middlewareCalls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = wc.ReportAccuracyWithContext(ctx, "Great Neck Plaza,NY,US", forecastedAt, forecast, "light rain")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Want error %v reporting accuracy with a cancelled context, got %v", context.Canceled, err)
+	}
+}
+
+// setCLITestServer starts a weather API test server which returns the Great
+// Neck forecast, and sets the environment variables RunCLI uses to query it.
+func setCLITestServer(t *testing.T) {
+	t.Helper()
+
+	// RunCLI creates its own HTTP client, so this test server does not use TLS.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write(testGreatNeckJSON)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	t.Setenv("OPENWEATHERMAP_API_KEY", testAPIKey)
+	t.Setenv("WEATHERCASTER_API_HOST", ts.URL)
+}
+
+func TestRunCLIAPIHost(t *testing.T) {
+	var gotPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, err := w.Write(testGreatNeckJSON)
+		if err != nil {
+			t.Errorf("unable to write test JSON to test HTTP server: %v", err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	t.Setenv("OPENWEATHERMAP_API_KEY", testAPIKey)
+	t.Setenv("WEATHERCASTER_API_HOST", ts.URL)
+
+	var output, errOutput bytes.Buffer
+	err := weather.RunCLI([]string{"-l", "London"}, strings.NewReader(""), &output, &errOutput)
+	if err != nil {
+		t.Fatalf("Error running CLI: %v, error output: %s", err, errOutput.String())
+	}
+	if want := "/data/2.5/forecast/"; want != gotPath {
+		t.Errorf("Want request path %q on WEATHERCASTER_API_HOST, got %q", want, gotPath)
+	}
+	const want = "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph\n"
+	if want != output.String() {
+		t.Errorf("Want %q, got %q", want, output.String())
+	}
+}
+
+func TestRunCLILocationsFromInput(t *testing.T) {
+	setCLITestServer(t)
+
+	input := strings.NewReader("Great Neck Plaza,NY,US\nLondon\n")
+	var output, errOutput bytes.Buffer
+
+	err := weather.RunCLI([]string{"-l", "-"}, input, &output, &errOutput)
+	if err != nil {
+		t.Fatalf("Error running CLI: %v, error output: %s", err, errOutput.String())
+	}
+
+	const wantLine = "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph"
+	want := wantLine + "\n" + wantLine + "\n"
+	if want != output.String() {
+		t.Errorf("Want %q, got %q", want, output.String())
+	}
+}
+
+func TestRunCLIVerbose(t *testing.T) {
+	setCLITestServer(t)
+
+	var output, errOutput bytes.Buffer
+	err := weather.RunCLI([]string{"-v", "-l", "London"}, strings.NewReader(""), &output, &errOutput)
+	if err != nil {
+		t.Fatalf("Error running CLI: %v, error output: %s", err, errOutput.String())
+	}
+
+	for _, want := range []string{`msg="weather API query"`, "status_code=200", "appid=REDACTED"} {
+		if !strings.Contains(errOutput.String(), want) {
+			t.Errorf("Want %q in verbose output, got %q", want, errOutput.String())
+		}
+	}
+	if !regexp.MustCompile(`(?m)^forecast fetched in [0-9.]+[mµn]?s$`).MatchString(errOutput.String()) {
+		t.Errorf("Want the elapsed time in verbose output, got %q", errOutput.String())
+	}
+	if output.Len() == 0 {
+		t.Error("Want a forecast in the output, got none")
+	}
+}
+
+func TestRunCLIInterrupted(t *testing.T) {
+	received := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(ts.Close)
+	t.Setenv("OPENWEATHERMAP_API_KEY", testAPIKey)
+	t.Setenv("WEATHERCASTER_API_HOST", ts.URL)
+
+	go func() {
+		<-received
+		err := syscall.Kill(os.Getpid(), syscall.SIGINT)
+		if err != nil {
+			t.Errorf("Error sending SIGINT: %v", err)
+		}
+	}()
+
+	var output, errOutput bytes.Buffer
+	err := weather.RunCLI([]string{"-l", "London"}, strings.NewReader(""), &output, &errOutput)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Want an error matching context.Canceled, got %v", err)
+	}
+	if want := "interrupted\n"; want != errOutput.String() {
+		t.Errorf("Want %q, got %q", want, errOutput.String())
+	}
+}
+
+func TestRunCLIColdWarning(t *testing.T) {
+	cold := strings.Replace(strings.Replace(string(testGreatNeckJSON), `"temp": 286,`, `"temp": 255,`, 1), `"feels_like": 285.74,`, `"feels_like": 249.5,`, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, cold)
+	}))
+	t.Cleanup(ts.Close)
+	t.Setenv("OPENWEATHERMAP_API_KEY", testAPIKey)
+	t.Setenv("WEATHERCASTER_API_HOST", ts.URL)
+
+	testCases := []struct {
+		description   string
+		args          []string
+		input         string
+		wantLines     int
+		wantErrOutput string
+	}{
+		{
+			description:   "one location",
+			args:          []string{"-l", "London"},
+			wantLines:     1,
+			wantErrOutput: "⚠️ COLD WARNING\n",
+		},
+		{
+			description:   "locations from input",
+			args:          []string{"-l", "-"},
+			input:         "London\nParis\n",
+			wantLines:     2,
+			wantErrOutput: "⚠️ COLD WARNING\n⚠️ COLD WARNING\n",
+		},
+		{
+			description:   "JSON",
+			args:          []string{"-l", "London", "-json"},
+			wantLines:     1,
+			wantErrOutput: "⚠️ COLD WARNING\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		var output, errOutput bytes.Buffer
+		err := weather.RunCLI(tc.args, strings.NewReader(tc.input), &output, &errOutput)
+		if err != nil {
+			t.Fatalf("Error running CLI: %v, error output: %s, testing %v", err, errOutput.String(), tc.description)
+		}
+
+		// Each forecast is one line of output, without the warning.
+		got := output.String()
+		if n := strings.Count(got, "\n"); tc.wantLines != n || strings.Contains(got, "WARNING") {
+			t.Errorf("Want %d lines of forecasts, got %q, testing %v", tc.wantLines, got, tc.description)
+		}
+		if tc.wantErrOutput != errOutput.String() {
+			t.Errorf("Want error output %q, got %q, testing %v", tc.wantErrOutput, errOutput.String(), tc.description)
+		}
+	}
+}
+
+func TestForecastYAML(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServerWithBody(t, testGreatNeckJSON)
+	wc, err := weather.NewClient(testAPIKey,
+		weather.WithHTTPClient(ts.Client()),
+		weather.WithAPIHost(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("Error while instanciating weather client: %v", err)
+	}
+
+	data, err := wc.ForecastYAML("Great Neck Plaza,NY,US")
+	if err != nil {
+		t.Fatalf("Error while getting YAML forecast: %v", err)
+	}
+	for _, want := range []string{"description: overcast clouds\n", "temp_unit: fahrenheit\n", "speed_unit: miles\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Want %q in YAML, got %s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "temp_min") {
+		t.Errorf("Want absent fields omitted from YAML, got %s", data)
+	}
+
+	var got weather.Conditions
+	err = yaml.Unmarshal(data, &got)
+	if err != nil {
+		t.Fatalf("Error unmarshaling YAML forecast: %v", err)
+	}
+	want := greatNeckConditions(t)
+	if !want.Equal(got) {
+		t.Errorf("Want %+v, got %+v", want, got)
+	}
+}
+
+func TestRunCLIYAML(t *testing.T) {
+	setCLITestServer(t)
+
+	var output, errOutput bytes.Buffer
+	err := weather.RunCLI([]string{"-yaml", "-l", "London"}, strings.NewReader(""), &output, &errOutput)
+	if err != nil {
+		t.Fatalf("Error running CLI: %v, error output: %s", err, errOutput.String())
+	}
+
+	var got weather.Conditions
+	err = yaml.Unmarshal(output.Bytes(), &got)
+	if err != nil {
+		t.Fatalf("Error unmarshaling YAML output %q: %v", output.String(), err)
+	}
+	if got.Description == nil || *got.Description != "overcast clouds" || got.TempUnit != weather.TempUnitFahrenheit {
+		t.Errorf("Want overcast clouds in Fahrenheit, got %+v", got)
+	}
+	if strings.HasSuffix(output.String(), "\n\n") {
+		t.Errorf("Want a single trailing newline, got %q", output.String())
+	}
+
+	err = weather.RunCLI([]string{"-yaml", "-json", "-l", "London"}, strings.NewReader(""), &output, &errOutput)
+	if err == nil {
+		t.Error("Want an error for both -yaml and -json, got nil")
+	}
+}
+
+func TestRunCLIJSON(t *testing.T) {
+	setCLITestServer(t)
+
+	testCases := []struct {
+		description string
+		flag        string
+		wantLines   int
+	}{
+		{
+			description: "compact",
+			flag:        "-json",
+			wantLines:   1,
+		},
+		{
+			description: "pretty",
+			flag:        "-json-pretty",
+			wantLines:   15,
+		},
+	}
+
+	for _, tc := range testCases {
+		var output, errOutput bytes.Buffer
+		err := weather.RunCLI([]string{tc.flag, "-l", "London"}, strings.NewReader(""), &output, &errOutput)
+		if err != nil {
+			t.Fatalf("Error running CLI: %v, error output: %s, testing %v", err, errOutput.String(), tc.description)
+		}
+
+		got := output.String()
+		if n := strings.Count(got, "\n"); tc.wantLines != n {
+			t.Errorf("Want %d lines, got %d: %q, testing %v", tc.wantLines, n, got, tc.description)
+		}
+
+		var w struct {
+			Description string
+		}
+		err = json.Unmarshal([]byte(got), &w)
+		if err != nil {
+			t.Fatalf("Error unmarshaling JSON output %q: %v, testing %v", got, err, tc.description)
+		}
+		if w.Description != "overcast clouds" {
+			t.Errorf("Want description %q, got %q, testing %v", "overcast clouds", w.Description, tc.description)
+		}
+	}
+}
+
+func TestRunCLIMeasurement(t *testing.T) {
+	setCLITestServer(t)
+	t.Setenv("WEATHERCASTER_MEASUREMENT", "metric,wind=imperial")
+	t.Setenv("WEATHERCASTER_TEMP_UNIT", "")
+	t.Setenv("WEATHERCASTER_SPEED_UNIT", "")
+
+	testCases := []struct {
+		description string
+		args        []string
+		want        string
+	}{
+		{
+			description: "measurement system",
+			args:        []string{"-l", "London"},
+			want:        "overcast clouds, temp 12.9 ºC, feels like 12.6 ºC, humidity 92.0%, wind 5.6 mph\n",
+		},
+		{
+			description: "flag takes precedence",
+			args:        []string{"-t", "f", "-l", "London"},
+			want:        "overcast clouds, temp 55.4 ºF, feels like 54.9 ºF, humidity 92.0%, wind 5.6 mph\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		var output, errOutput bytes.Buffer
+		err := weather.RunCLI(tc.args, strings.NewReader(""), &output, &errOutput)
+		if err != nil {
+			t.Fatalf("Error running CLI: %v, error output: %s, testing %v", err, errOutput.String(), tc.description)
+		}
+		if tc.want != output.String() {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, output.String(), tc.description)
+		}
+	}
+}
+
+func TestRunCLIVersion(t *testing.T) {
+	// Unset the API key to verify the version flag does not require one.
+	t.Setenv("OPENWEATHERMAP_API_KEY", "")
+
+	var output, errOutput bytes.Buffer
+	err := weather.RunCLI([]string{"--version"}, strings.NewReader(""), &output, &errOutput)
+	if err != nil {
+		t.Fatalf("Error running CLI: %v", err)
+	}
+
+	want := "weathercaster version " + weather.Version() + "\n"
+	if want != output.String() {
+		t.Errorf("Want %q, got %q", want, output.String())
+	}
+}
+
+func TestRunCLIConvertKelvin(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		args        []string
+		input       string
+		want        string
+	}{
+		{
+			description: "arguments to fahrenheit",
+			args:        []string{"-convert-kelvin", "-t", "f", "286", "273"},
+			want:        "55.4 ºF\n32.0 ºF\n",
+		},
+		{
+			description: "arguments to celsius",
+			args:        []string{"-convert-kelvin", "-t", "c", "286", "273.15"},
+			want:        "12.9 ºC\n0.0 ºC\n",
+		},
+		{
+			description: "input to kelvin",
+			args:        []string{"-convert-kelvin", "-t", "k"},
+			input:       "286\n285.74\n",
+			want:        "286.0K\n285.7K\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		var output, errOutput bytes.Buffer
+		err := weather.RunCLI(tc.args, strings.NewReader(tc.input), &output, &errOutput)
+		if err != nil {
+			t.Fatalf("Error running CLI for test %v: %v", tc.description, err)
+		}
+
+		if tc.want != output.String() {
+			t.Errorf("Want %q, got %q, testing %v", tc.want, output.String(), tc.description)
+		}
+	}
+}
+
+func TestProcessCLISpeedUnit(t *testing.T) {
+	t.Parallel()
+
+	// Define test cases
+	testCases := []struct {
+		userInput   string
+		want        weather.SpeedUnit
+		errExpected bool
+	}{
+		{
+			userInput: "", // default case
+			want:      weather.SpeedUnitMiles,
+		},
+		{
+			userInput: "meters",
+			want:      weather.SpeedUnitMeters,
+		},
+		{
+			userInput: "miles",
+			want:      weather.SpeedUnitMiles,
+		},
+		{
+			userInput: "METERS",
+			want:      weather.SpeedUnitMeters,
+		},
+		{
+			userInput: "Meters",
+			want:      weather.SpeedUnitMeters,
+		},
+		{
+			userInput: "MILES",
+			want:      weather.SpeedUnitMiles,
+		},
+		{
+			userInput: "Miles",
+			want:      weather.SpeedUnitMiles,
+		},
+		{
+			userInput:   "feet",
+			errExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := weather.ProcessCLISpeedUnit(tc.userInput)
+		if !tc.errExpected && err != nil {
+			t.Fatalf("error for user input %q: %v", tc.userInput, err)
+		}
+
+		if !tc.errExpected && tc.want != got {
+			t.Fatalf("want %q, got %q, for user input %q", tc.want, got, tc.userInput)
+		}
+	}
+}
+
+func TestProcessCLITempUnit(t *testing.T) {
+	t.Parallel()
+
+	// Define test cases
+	testCases := []struct {
+		userInput   string
+		want        weather.TempUnit
+		errExpected bool
+	}{
+		{
+			userInput: "", // default case
+			want:      weather.TempUnitFahrenheit,
+		},
+		{
+			userInput: "f",
+			want:      weather.TempUnitFahrenheit,
+		},
+		{
+			userInput: "c",
+			want:      weather.TempUnitCelsius,
+		},
+		{
+			userInput: "k",
+			want:      weather.TempUnitKelvin,
+		},
+		{
+			userInput: "C",
+			want:      weather.TempUnitCelsius,
+		},
+		{
+			userInput: "Celsius",
+			want:      weather.TempUnitCelsius,
+		},
+		{
+			userInput: "FAHRENHEIT",
+			want:      weather.TempUnitFahrenheit,
+		},
+		{
+			userInput: "K",
+			want:      weather.TempUnitKelvin,
+		},
+		{
+			userInput:   "x",
+			errExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := weather.ProcessCLITempUnit(tc.userInput)
+		if !tc.errExpected && err != nil {
+			t.Fatalf("error for user input %q: %v", tc.userInput, err)
+		}
+
+		if !tc.errExpected && tc.want != got {
+			t.Fatalf("want %q, got %q, for user input %q", tc.want, got, tc.userInput)
+		}
+	}
+}
+
+func TestProcessCLIMeasurement(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		userInput   string
+		wantTemp    weather.TempUnit
+		wantSpeed   weather.SpeedUnit
+		errExpected bool
+	}{
+		{
+			userInput: "metric",
+			wantTemp:  weather.TempUnitCelsius,
+			wantSpeed: weather.SpeedUnitMeters,
+		},
+		{
+			userInput: "Imperial",
+			wantTemp:  weather.TempUnitFahrenheit,
+			wantSpeed: weather.SpeedUnitMiles,
+		},
+		{
+			userInput: "metric,wind=imperial",
+			wantTemp:  weather.TempUnitCelsius,
+			wantSpeed: weather.SpeedUnitMiles,
+		},
+		{
+			userInput: "imperial, temp=k, wind=meters",
+			wantTemp:  weather.TempUnitKelvin,
+			wantSpeed: weather.SpeedUnitMeters,
+		},
+		{
+			userInput:   "",
+			errExpected: true,
+		},
+		{
+			userInput:   "wind=imperial",
+			errExpected: true,
+		},
+		{
+			userInput:   "metric,pressure=imperial",
+			errExpected: true,
+		},
+		{
+			userInput:   "metric,temp=",
+			errExpected: true,
+		},
+		{
+			userInput:   "metric,temp=x",
+			errExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		gotTemp, gotSpeed, err := weather.ProcessCLIMeasurement(tc.userInput)
+		errReceived := err != nil
+		if tc.errExpected != errReceived {
+			t.Fatalf("Want error %v, got %v, for user input %q", tc.errExpected, err, tc.userInput)
+		}
+		if tc.wantTemp != gotTemp || tc.wantSpeed != gotSpeed {
+			t.Errorf("Want %v and %v, got %v and %v, for user input %q", tc.wantTemp, tc.wantSpeed, gotTemp, gotSpeed, tc.userInput)
+		}
+	}
+}
+
+func TestParseForecastJSONPresence(t *testing.T) {
+	t.Parallel()
+
+	// Humidity is null, and feels_like and wind are absent.
+	data := []byte(`{"list":[{"weather":[{"description":"overcast clouds"}],"main":{"temp":286,"humidity":null}}]}`)
+
+	got, err := weather.ParseForecastJSON(data)
+	if err != nil {
+		t.Fatalf("Error parsing forecast JSON: %v", err)
+	}
+
+	if got.Temperature == nil || *got.Temperature != 286 {
+		t.Errorf("Want temperature 286, got %v", got.Temperature)
+	}
+	if got.FeelsLike != nil || got.Humidity != nil || got.WindSpeed != nil {
+		t.Errorf("Want absent and null fields to be nil, got feels like %v, humidity %v, wind speed %v", got.FeelsLike, got.Humidity, got.WindSpeed)
+	}
+
+	_, err = weather.ParseForecastJSON([]byte(`{"list":[{"weather":[{}],"main":{"temp":"warm"}}]}`))
+	if err == nil {
+		t.Errorf("Want an error parsing a temperature which is not a number, got nil")
+	}
+}
+
+// BenchmarkParseForecastJSON measures parsing without HTTP. Decoding numbers
+// into owmFloat values, instead of a *float64 per field, reduced this from 10
+// to 5 allocs/op with no change in ns/op.
+func BenchmarkParseForecastJSON(b *testing.B) {
+	data := testGreatNeckJSON
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := weather.ParseForecastJSON(data)
+		if err != nil {
+			b.Fatalf("Error parsing forecast JSON: %v", err)
 		}
 	}
 }
@@ -0,0 +1,71 @@
+package weather
+
+import (
+	"fmt"
+	"math"
+)
+
+// beaufortScale stores the description of each Beaufort number, and the
+// wind speed in meters/sec at which the next number begins.
+var beaufortScale = []struct {
+	description string
+	below       float64
+}{
+	{"Calm", 0.5},
+	{"Light air", 1.6},
+	{"Light breeze", 3.4},
+	{"Gentle breeze", 5.5},
+	{"Moderate breeze", 8.0},
+	{"Fresh breeze", 10.8},
+	{"Strong breeze", 13.9},
+	{"Near gale", 17.2},
+	{"Gale", 20.8},
+	{"Strong gale", 24.5},
+	{"Storm", 28.5},
+	{"Violent storm", 32.7},
+	{"Hurricane force", math.Inf(1)},
+}
+
+// beaufort returns the Beaufort number for a wind speed in meters/sec.
+func beaufort(meters float64) int {
+	for i, b := range beaufortScale {
+		if meters < b.below {
+			return i
+		}
+	}
+	return len(beaufortScale) - 1
+}
+
+// compassPoints stores the eight principal compass directions, clockwise from
+// north.
+var compassPoints = []string{"north", "northeast", "east", "southeast", "south", "southwest", "west", "northwest"}
+
+// compassPoint returns the nearest of the eight principal compass directions
+// to a direction in degrees.
+func compassPoint(deg float64) string {
+	i := int(math.Round(math.Mod(deg, 360)/45)) % len(compassPoints)
+	if i < 0 {
+		i += len(compassPoints)
+	}
+	return compassPoints[i]
+}
+
+// WindDescription returns wind as a sentence using the Beaufort scale, such
+// as "Moderate breeze from the northwest at 14 mph," with the speed rounded
+// to a whole number. The direction is omitted when it is not present, such as
+// "Moderate breeze at 14 mph," and calm wind is only described as "Calm." An
+// empty string is returned if wind speed is not present.
+func (w Conditions) WindDescription() string {
+	if w.WindSpeed == nil {
+		return ""
+	}
+
+	b := beaufortScale[beaufort(speedToMeters(*w.WindSpeed, w.SpeedUnit))].description
+	if b == beaufortScale[0].description {
+		return b
+	}
+	if w.WindDirection != nil {
+		b += " from the " + compassPoint(*w.WindDirection)
+	}
+	return fmt.Sprintf("%s at %.0f %s", b, *w.WindSpeed, speedUnitName[w.SpeedUnit])
+}
@@ -0,0 +1,59 @@
+package weather_test
+
+import (
+	"testing"
+	"weather"
+)
+
+func TestWindDescription(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		speed     float64
+		direction *float64
+		want      string
+	}{
+		{speed: 0, want: "Calm"},
+		{speed: 1, direction: float64Ptr(0), want: "Light air from the north at 1 m/s"},
+		{speed: 2, direction: float64Ptr(44), want: "Light breeze from the northeast at 2 m/s"},
+		{speed: 4, direction: float64Ptr(90), want: "Gentle breeze from the east at 4 m/s"},
+		{speed: 6.3, direction: float64Ptr(315), want: "Moderate breeze from the northwest at 6 m/s"},
+		{speed: 9, direction: float64Ptr(135), want: "Fresh breeze from the southeast at 9 m/s"},
+		{speed: 12, direction: float64Ptr(180), want: "Strong breeze from the south at 12 m/s"},
+		{speed: 15, direction: float64Ptr(225), want: "Near gale from the southwest at 15 m/s"},
+		{speed: 19, direction: float64Ptr(270), want: "Gale from the west at 19 m/s"},
+		{speed: 22, direction: float64Ptr(359), want: "Strong gale from the north at 22 m/s"},
+		{speed: 26, want: "Storm at 26 m/s"},
+		{speed: 30, want: "Violent storm at 30 m/s"},
+		{speed: 40, want: "Hurricane force at 40 m/s"},
+	}
+
+	for _, tc := range testCases {
+		w := weather.Conditions{
+			WindSpeed:     float64Ptr(tc.speed),
+			WindDirection: tc.direction,
+			SpeedUnit:     weather.SpeedUnitMeters,
+		}
+		got := w.WindDescription()
+		if tc.want != got {
+			t.Errorf("Want %q, got %q", tc.want, got)
+		}
+	}
+
+	// The Beaufort number is determined independently of the speed unit.
+	w := weather.Conditions{
+		WindSpeed:     float64Ptr(14),
+		WindDirection: float64Ptr(320),
+		SpeedUnit:     weather.SpeedUnitMiles,
+	}
+	const want = "Moderate breeze from the northwest at 14 mph"
+	got := w.WindDescription()
+	if want != got {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+
+	got = weather.Conditions{}.WindDescription()
+	if got != "" {
+		t.Errorf("Want an empty string without wind speed, got %q", got)
+	}
+}